@@ -0,0 +1,45 @@
+package ectoinject
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// GetAll gets every dependency registered for T. Returns a context with scoped dependencies caching, the dependencies, and an error.
+// Dependencies are ordered by priority, then by registration order
+// T: The type of the dependencies
+// ctx: The context to use. To use a non-default container, use SetActiveContainer
+func GetAll[T any](ctx context.Context) (context.Context, []T, error) {
+	activeContainer, err := GetActiveContainer(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	ctx, instances, err := activeContainer.GetAll(ctx, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	vals := make([]T, len(instances))
+	for i, instance := range instances {
+		vals[i], err = ectoreflect.Cast[T](instance)
+		if err != nil {
+			return ctx, nil, err
+		}
+	}
+
+	return ctx, vals, nil
+}
+
+// SetPriority sets the priority of a dependency in collections. Dependencies with a higher priority come first in []T fields, args and GetAll.
+// Dependencies with the same priority keep their registration order
+// T: The type the dependency is registered as
+// container: The container the dependency is registered in
+// name: (optional) The name of the dependency
+// priority: The priority of the dependency. Defaults to 0
+func SetPriority[T any](container ectocontainer.DIContainer, name string, priority int) error {
+	return container.SetPriority(reflect.TypeOf((*T)(nil)).Elem(), name, priority)
+}
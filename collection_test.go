@@ -0,0 +1,145 @@
+package ectoinject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type team struct {
+	Members []Person          `inject:""`
+	ByName  map[string]Person `inject:""`
+	Pets    []Animal          `inject:",optional"`
+}
+
+type roster struct {
+	names []string
+}
+
+func (r *roster) Constructor(people []Person) *roster {
+	names := make([]string, len(people))
+	for i, p := range people {
+		names[i] = p.(*Human).Name
+	}
+	return &roster{names: names}
+}
+
+func TestCollectionInjection(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test collection injection"})
+	assert.Nil(t, err, "error creating container")
+
+	for _, name := range []string{"peter", "ray", "egon"} {
+		err = RegisterInstance[Person](container, &Human{Name: name}, name)
+		assert.Nil(t, err, "error registering person")
+	}
+
+	err = RegisterTransient[team, team](container)
+	assert.Nil(t, err, "error registering team")
+
+	err = RegisterTransient[roster, roster](container)
+	assert.Nil(t, err, "error registering roster")
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test collection injection")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, tm, err := GetContext[*team](ctx)
+	assert.Nil(t, err, "error getting team")
+	assert.Len(t, tm.Members, 3)
+	assert.Equal(t, "peter", tm.Members[0].(*Human).Name, "members should be in registration order")
+	assert.Equal(t, "egon", tm.Members[2].(*Human).Name, "members should be in registration order")
+	assert.Equal(t, "ray", tm.ByName["ray"].(*Human).Name)
+	assert.Empty(t, tm.Pets, "optional collections without registrations should be left empty")
+
+	ctx, r, err := GetContext[*roster](ctx)
+	assert.Nil(t, err, "error getting roster")
+	assert.Equal(t, []string{"peter", "ray", "egon"}, r.names)
+
+	_, people, err := GetAll[Person](ctx)
+	assert.Nil(t, err, "error getting all people")
+	assert.Len(t, people, 3)
+}
+
+func TestCollectionPriority(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test collection priority"})
+	assert.Nil(t, err, "error creating container")
+
+	for _, name := range []string{"peter", "ray", "egon"} {
+		err = RegisterInstance[Person](container, &Human{Name: name}, name)
+		assert.Nil(t, err, "error registering person")
+	}
+
+	err = RegisterTransient[team, team](container)
+	assert.Nil(t, err, "error registering team")
+
+	err = RegisterTransient[roster, roster](container)
+	assert.Nil(t, err, "error registering roster")
+
+	assert.Nil(t, SetPriority[Person](container, "egon", 10))
+	assert.Nil(t, SetPriority[Person](container, "peter", -1))
+
+	err = SetPriority[Person](container, "slimer", 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ctx, err := SetActiveContainer(context.Background(), "test collection priority")
+	assert.Nil(t, err, "error setting active container")
+
+	_, r, err := GetContext[*roster](ctx)
+	assert.Nil(t, err, "error getting roster")
+	assert.Equal(t, []string{"egon", "ray", "peter"}, r.names)
+}
+
+func TestValidateCollection(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test validate collection"})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstanceFunc[Person](container, lifecycles.Transient, func(ctx context.Context) (any, error) {
+		return &Human{Name: "peter"}, nil
+	}, "peter")
+	assert.Nil(t, err, "error registering person")
+
+	err = RegisterSingleton[team, team](container)
+	assert.Nil(t, err, "error registering team")
+
+	var captiveErr *CaptiveDependencyError
+	assert.ErrorAs(t, container.Validate(), &captiveErr, "collections should be checked for captive dependencies")
+	assert.Equal(t, "peter", captiveErr.Child)
+}
+
+type petShelter struct {
+	Pets []Animal `inject:""`
+}
+
+type petNames struct {
+	count int
+}
+
+func (p *petNames) Constructor(pets map[string]Animal) *petNames {
+	return &petNames{count: len(pets)}
+}
+
+func TestMissingCollection(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test missing collection", AllowMissingDependencies: false})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterTransient[petShelter, petShelter](container)
+	assert.Nil(t, err, "error registering pet shelter")
+
+	err = RegisterTransient[petNames, petNames](container)
+	assert.Nil(t, err, "error registering pet names")
+
+	assert.ErrorIs(t, container.Validate(), ErrNotFound)
+
+	ctx, err := SetActiveContainer(context.Background(), "test missing collection")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, _, err = GetContext[*petShelter](ctx)
+	assert.ErrorIs(t, err, ErrNotFound, "collections without registrations should be missing")
+
+	_, _, err = GetContext[*petNames](ctx)
+	assert.ErrorIs(t, err, ErrNotFound, "collection args without registrations should be missing")
+}
@@ -0,0 +1,226 @@
+package ectoinject
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type slowHuman struct {
+	Human
+}
+
+type ping struct {
+	Pong *pong `inject:""`
+}
+
+type pong struct {
+	Ping *ping `inject:""`
+}
+
+func TestConcurrentSingletonIsBuiltOnce(t *testing.T) {
+	type house struct {
+		Dad *slowHuman `inject:""`
+	}
+
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test concurrent singleton",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	var built atomic.Int32
+	err = RegisterInstanceFunc[slowHuman](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		built.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return &slowHuman{}, nil
+	})
+	assert.Nil(t, err, "error registering dad dependency")
+
+	err = RegisterTransient[house, house](container)
+	assert.Nil(t, err, "error registering house dependency")
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	var wg sync.WaitGroup
+	dads := make([]*slowHuman, 50)
+	errs := make([]error, 50)
+	for i := range dads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, houseVal, err := GetContext[house](ctx)
+			dads[i], errs[i] = houseVal.Dad, err
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), built.Load(), "singleton was built more than once")
+	for i := range dads {
+		assert.Nil(t, errs[i], "error getting house instance")
+		assert.Same(t, dads[0], dads[i], "singleton instances do not match")
+	}
+}
+
+func TestConcurrentRegistrationAndResolution(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test concurrent registration",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterScoped[Person, Human](container)
+	assert.Nil(t, err, "error registering person dependency")
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			err := RegisterTransient[Animal, Dog](container, fmt.Sprintf("dog-%d", i))
+			assert.Nil(t, err, "error registering dog dependency")
+		}()
+		go func() {
+			defer wg.Done()
+			_, person, err := GetContext[Person](ctx)
+			assert.Nil(t, err, "error getting person instance")
+			assert.Equal(t, "hello", person.Speak())
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		_, dog, err := GetNamedDependency[Animal](ctx, fmt.Sprintf("dog-%d", i))
+		assert.Nil(t, err, "error getting dog instance")
+		assert.Equal(t, "woof", dog.Speak())
+	}
+}
+
+func TestConcurrentCircularDependencyDoesNotDeadlock(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test concurrent circular dependency",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterDependency[ping, ping](container, lifecycles.Singleton)
+	assert.Nil(t, err, "error registering ping dependency")
+
+	err = RegisterDependency[pong, pong](container, lifecycles.Singleton)
+	assert.Nil(t, err, "error registering pong dependency")
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, _, err := GetContext[ping](ctx)
+				assert.ErrorContains(t, err, "circular dependency detected")
+			}()
+			go func() {
+				defer wg.Done()
+				_, _, err := GetContext[pong](ctx)
+				assert.ErrorContains(t, err, "circular dependency detected")
+			}()
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("concurrent resolution of a circular dependency deadlocked")
+	}
+}
+
+type diamondTop struct {
+	Bottom *diamondBottom `inject:""`
+	Left   *diamondLeft   `inject:""`
+}
+
+type diamondLeft struct {
+	Bottom *diamondBottom `inject:""`
+}
+
+type diamondBottom struct {
+}
+
+func TestConcurrentDiamondIsNotCircular(t *testing.T) {
+	// a single thread makes the resolutions interleave at every wait
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	for i := 0; i < 20; i++ {
+		config := ectocontainer.DIContainerConfig{
+			ID:                       fmt.Sprintf("test concurrent diamond %d", i),
+			AllowCaptiveDependencies: true,
+			AllowMissingDependencies: true,
+		}
+
+		container, err := NewDIContainer(config)
+		assert.Nil(t, err, "error creating container")
+
+		release := make(chan struct{})
+		err = RegisterInstanceFunc[diamondBottom](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+			<-release
+			return &diamondBottom{}, nil
+		})
+		assert.Nil(t, err, "error registering bottom dependency")
+
+		err = RegisterSingleton[diamondLeft, diamondLeft](container)
+		assert.Nil(t, err, "error registering left dependency")
+
+		err = RegisterSingleton[diamondTop, diamondTop](container)
+		assert.Nil(t, err, "error registering top dependency")
+
+		ctx, err := SetActiveContainer(context.Background(), config.ID)
+		assert.Nil(t, err, "error setting active container")
+
+		// the top claims the bottom, then the left claims itself and waits on the bottom
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, err := GetContext[*diamondTop](ctx)
+			assert.Nil(t, err, "error getting top instance")
+		}()
+		time.Sleep(time.Millisecond)
+		go func() {
+			defer wg.Done()
+			_, _, err := GetContext[*diamondLeft](ctx)
+			assert.Nil(t, err, "error getting left instance")
+		}()
+		time.Sleep(time.Millisecond)
+
+		// once the bottom is built, the top waits on the left while the left has not yet woken up
+		close(release)
+		wg.Wait()
+	}
+}
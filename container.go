@@ -24,6 +24,7 @@ var DefaultContainerConfig = ectocontainer.DIContainerConfig{
 	AllowUnsafeDependencies:  false,
 	ConstructorFuncName:      "Constructor",
 	InjectTagName:            "inject",
+	InitFuncName:             "Init",
 	LoggerConfig:             &DefaulLoggerConfig,
 }
 
@@ -35,6 +36,7 @@ var DefaultContainerConfig = ectocontainer.DIContainerConfig{
 // AllowUnsafeDependencies: false
 // ConstructorFuncName: "Constructor"
 // InjectTagName: "inject"
+// InitFuncName: "Init"
 func NewDIDefaultContainer() (ectocontainer.DIContainer, error) {
 	return NewDIContainer(DefaultContainerConfig)
 }
@@ -58,6 +60,14 @@ func NewDIContainer(config ectocontainer.DIContainerConfig) (ectocontainer.DICon
 		config.ConstructorFuncName = "Constructor"
 	}
 
+	// "-" disables the init func
+	switch config.InitFuncName {
+	case "":
+		config.InitFuncName = "Init"
+	case "-":
+		config.InitFuncName = ""
+	}
+
 	loggerConfig := config.LoggerConfig
 	logger, err := logging.NewLogger(loggerConfig.Prefix, loggerConfig.LogLevel, loggerConfig.EnableColor, loggerConfig.Enabled, loggerConfig.LogFunc)
 	if err != nil {
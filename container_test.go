@@ -0,0 +1,552 @@
+package ectoinject
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/internal/scope"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type database struct {
+	Log *eventLog `inject:""`
+}
+
+func (d *database) Close() error {
+	d.Log.add("database")
+	return nil
+}
+
+type repository struct {
+	Log *eventLog `inject:""`
+	DB  *database `inject:""`
+}
+
+func (r *repository) Close() error {
+	r.Log.add("repository")
+	return nil
+}
+
+type server struct {
+	Log  *eventLog   `inject:""`
+	Repo *repository `inject:""`
+}
+
+func (s *server) Shutdown(ctx context.Context) error {
+	s.Log.add("server")
+	return fmt.Errorf("server shutdown failed")
+}
+
+type stuckServer struct {
+}
+
+func (s *stuckServer) Shutdown(ctx context.Context) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestCloseContainer(t *testing.T) {
+	log := &eventLog{}
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test close container",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		DisposeFuncName:          "Shutdown",
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstance[*eventLog](container, log)
+	assert.Nil(t, err, "error registering log")
+
+	err = RegisterSingleton[database, database](container)
+	assert.Nil(t, err, "error registering database")
+
+	err = RegisterSingleton[repository, repository](container)
+	assert.Nil(t, err, "error registering repository")
+
+	err = RegisterSingleton[server, server](container)
+	assert.Nil(t, err, "error registering server")
+
+	// an instance owned by the caller is never disposed
+	err = RegisterInstance[*closer](container, &closer{name: "instance", log: log}, "instance")
+	assert.Nil(t, err, "error registering instance")
+
+	registerClosers(t, container, lifecycles.Scoped, log, "scoped")
+
+	_, err = GetFromContainer[*server](container.GetContainerID())
+	assert.Nil(t, err, "error getting server")
+
+	ctx, err := SetActiveContainer(context.Background(), container.GetContainerID())
+	assert.Nil(t, err, "error setting active container")
+
+	_, _, err = GetNamedDependency[*closer](scope.WithRootScope(ctx), "scoped")
+	assert.Nil(t, err, "error getting scoped closer")
+	_, _, err = GetNamedDependency[*closer](ctx, "instance")
+	assert.Nil(t, err, "error getting instance closer")
+
+	err = container.Close(context.Background())
+	assert.ErrorContains(t, err, "failed to dispose dependency 'github.com/Gobusters/ectoinject.server' with 'Shutdown' func: server shutdown failed")
+	assert.Equal(t, []string{"scoped", "server", "repository", "database"}, log.get(), "instances were not disposed in reverse dependency order")
+
+	_, err = GetFromContainer[*server](container.GetContainerID())
+	assert.ErrorContains(t, err, "container 'test close container' is closed")
+
+	// closing again is a no-op
+	assert.Nil(t, container.Close(context.Background()))
+}
+
+func TestCloseContainerDeadline(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test close container deadline",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		DisposeFuncName:          "Shutdown",
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[stuckServer, stuckServer](container)
+	assert.Nil(t, err, "error registering stuck server")
+
+	_, err = GetFromContainer[*stuckServer](config.ID)
+	assert.Nil(t, err, "error getting stuck server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = container.Close(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second/2, "close did not honor the context deadline")
+}
+
+type warmCache struct {
+	Log *eventLog `inject:""`
+}
+
+func (c *warmCache) Start(ctx context.Context) error {
+	c.Log.add("start cache")
+	return nil
+}
+
+func (c *warmCache) Stop(ctx context.Context) error {
+	c.Log.add("stop cache")
+	return nil
+}
+
+type migrations struct {
+	Log *eventLog `inject:""`
+}
+
+func (m *migrations) Start(ctx context.Context) error {
+	m.Log.add("run migrations")
+	return nil
+}
+
+type httpServer struct {
+	Log        *eventLog   `inject:""`
+	Cache      *warmCache  `inject:""`
+	Migrations *migrations `inject:""`
+}
+
+func (s *httpServer) Start(ctx context.Context) error {
+	s.Log.add("start server")
+	return nil
+}
+
+func (s *httpServer) Stop(ctx context.Context) error {
+	s.Log.add("stop server")
+	return nil
+}
+
+type brokenServer struct {
+	Log   *eventLog  `inject:""`
+	Cache *warmCache `inject:""`
+}
+
+func (s *brokenServer) Start(ctx context.Context) error {
+	return fmt.Errorf("port in use")
+}
+
+type worker struct {
+	Log *eventLog `inject:""`
+}
+
+func (w *worker) Run() {
+	w.Log.add("run worker")
+}
+
+func (w *worker) Halt(ctx context.Context) error {
+	w.Log.add("halt worker")
+	return nil
+}
+
+func TestStartAndStopContainer(t *testing.T) {
+	log := &eventLog{}
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test start and stop container",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstance[*eventLog](container, log)
+	assert.Nil(t, err, "error registering log")
+
+	err = RegisterSingleton[warmCache, warmCache](container)
+	assert.Nil(t, err, "error registering cache")
+
+	err = RegisterSingleton[httpServer, httpServer](container)
+	assert.Nil(t, err, "error registering server")
+
+	err = RegisterSingleton[migrations, migrations](container)
+	assert.Nil(t, err, "error registering migrations")
+
+	err = container.Start(context.Background())
+	assert.Nil(t, err, "error starting container")
+	// the cache and the migrations do not depend on each other so they may start in any order, but both start before the server
+	started := log.get()
+	if assert.Len(t, started, 3) {
+		assert.ElementsMatch(t, []string{"start cache", "run migrations"}, started[:2])
+		assert.Equal(t, "start server", started[2])
+	}
+
+	err = container.Start(context.Background())
+	assert.ErrorContains(t, err, "has already been started")
+
+	err = container.Stop(context.Background())
+	assert.Nil(t, err, "error stopping container")
+	assert.Equal(t, append(started, "stop server", "stop cache"), log.get())
+
+	// stopping again is a no-op
+	assert.Nil(t, container.Stop(context.Background()))
+}
+
+func TestStartContainerRollback(t *testing.T) {
+	log := &eventLog{}
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test start container rollback",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstance[*eventLog](container, log)
+	assert.Nil(t, err, "error registering log")
+
+	err = RegisterSingleton[warmCache, warmCache](container)
+	assert.Nil(t, err, "error registering cache")
+
+	err = RegisterSingleton[brokenServer, brokenServer](container)
+	assert.Nil(t, err, "error registering server")
+
+	err = container.Start(context.Background())
+	assert.ErrorContains(t, err, "failed to start dependency 'github.com/Gobusters/ectoinject.brokenServer': port in use")
+	assert.Equal(t, []string{"start cache", "stop cache"}, log.get(), "started singletons were not rolled back")
+
+	// the container was not started so there is nothing to stop
+	assert.Nil(t, container.Stop(context.Background()))
+	assert.Equal(t, []string{"start cache", "stop cache"}, log.get())
+}
+
+func TestStartContainerWithHookNames(t *testing.T) {
+	log := &eventLog{}
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test start container with hook names",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		StartFuncName:            "Run",
+		StopFuncName:             "Halt",
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstance[*eventLog](container, log)
+	assert.Nil(t, err, "error registering log")
+
+	err = RegisterSingleton[warmCache, warmCache](container)
+	assert.Nil(t, err, "error registering cache")
+
+	err = RegisterSingleton[worker, worker](container)
+	assert.Nil(t, err, "error registering worker")
+
+	err = container.Start(context.Background())
+	assert.Nil(t, err, "error starting container")
+	assert.Equal(t, []string{"run worker"}, log.get(), "only the configured hooks should be called")
+
+	// closing a started container stops it
+	err = container.Close(context.Background())
+	assert.Nil(t, err, "error closing container")
+	assert.Equal(t, []string{"run worker", "halt worker"}, log.get())
+}
+
+func TestStartContainerSkipsReplacedSingletons(t *testing.T) {
+	log := &eventLog{}
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test start container skips replaced singletons",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstance[*eventLog](container, log)
+	assert.Nil(t, err, "error registering log")
+
+	err = RegisterSingleton[warmCache, warmCache](container)
+	assert.Nil(t, err, "error registering cache")
+
+	_, err = GetFromContainer[*warmCache](config.ID)
+	assert.Nil(t, err, "error getting cache")
+
+	// replacing the registration forgets the cached instance, so only the new instance is started and stopped
+	err = RegisterSingleton[warmCache, warmCache](container)
+	assert.Nil(t, err, "error registering cache")
+
+	err = container.Start(context.Background())
+	assert.Nil(t, err, "error starting container")
+	assert.Equal(t, []string{"start cache"}, log.get(), "replaced singletons should not be started")
+
+	err = container.Stop(context.Background())
+	assert.Nil(t, err, "error stopping container")
+	assert.Equal(t, []string{"start cache", "stop cache"}, log.get(), "replaced singletons should not be stopped")
+}
+
+type panickyMonkey struct {
+}
+
+func (m *panickyMonkey) Speak() string {
+	return "ooh"
+}
+
+func (m *panickyMonkey) Constructor(dep Animal, missing *database) *panickyMonkey {
+	panic("constructors must not be called by Validate")
+}
+
+func TestValidateContainer(t *testing.T) {
+	type kennel struct {
+		Pet   *Dog   `inject:"cat"`
+		Owner Person `inject:"john"`
+		Mouse Animal `inject:"mouse"`
+	}
+
+	type zoo struct {
+		Monkey *panickyMonkey `inject:""`
+		Kennel kennel         `inject:""`
+	}
+
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test validate container",
+		AllowCaptiveDependencies: false,
+		AllowMissingDependencies: false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[Animal, Cat](container, "cat")
+	assert.Nil(t, err, "error registering cat")
+
+	err = RegisterTransient[Person, Human](container, "john")
+	assert.Nil(t, err, "error registering john")
+
+	err = RegisterSingleton[Animal, circularAnimal](container, "foo")
+	assert.Nil(t, err, "error registering foo")
+
+	err = RegisterInstanceFunc[Animal](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		panic("instance funcs must not be called by Validate")
+	})
+	assert.Nil(t, err, "error registering animal")
+
+	err = RegisterSingleton[panickyMonkey, panickyMonkey](container)
+	assert.Nil(t, err, "error registering monkey")
+
+	err = RegisterSingleton[kennel, kennel](container)
+	assert.Nil(t, err, "error registering kennel")
+
+	err = RegisterTransient[zoo, zoo](container)
+	assert.Nil(t, err, "error registering zoo")
+
+	err = container.Validate()
+	assert.NotNil(t, err, "no error returned for invalid container")
+	assert.ErrorContains(t, err, "field 'Pet' on dependency 'github.com/Gobusters/ectoinject.kennel' has type '*ectoinject.Dog' which cannot be assigned dependency 'cat' of type '*ectoinject.Cat'")
+	assert.ErrorContains(t, err, "github.com/Gobusters/ectoinject.kennel has a dependency on mouse, but it is not registered")
+	assert.ErrorContains(t, err, "dependency 'github.com/Gobusters/ectoinject.panickyMonkey' has unregistered dependency 'github.com/Gobusters/ectoinject.database' in 'Constructor' func")
+	assert.ErrorContains(t, err, "circular dependency detected for 'foo'. Dependency chain: foo -> foo")
+	assert.ErrorContains(t, err, "captive dependency error: github.com/Gobusters/ectoinject.kennel is a singleton but has a transient dependency john")
+	assert.ErrorContains(t, err, "john has a dependency on .string, but it is not registered")
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 6, "unexpected number of problems")
+}
+
+func TestValidateValidContainer(t *testing.T) {
+	log := &eventLog{}
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test validate valid container",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		DisposeFuncName:          "Shutdown",
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstance[*eventLog](container, log)
+	assert.Nil(t, err, "error registering log")
+
+	err = RegisterSingleton[database, database](container)
+	assert.Nil(t, err, "error registering database")
+
+	err = RegisterSingleton[repository, repository](container)
+	assert.Nil(t, err, "error registering repository")
+
+	err = RegisterSingleton[server, server](container)
+	assert.Nil(t, err, "error registering server")
+
+	err = RegisterSingleton[monkey, monkey](container)
+	assert.Nil(t, err, "error registering monkey")
+
+	err = RegisterSingleton[Animal, Dog](container)
+	assert.Nil(t, err, "error registering dog")
+
+	assert.Nil(t, container.Validate())
+	assert.Empty(t, log.get())
+}
+
+type pair struct {
+	Left  *closer `inject:"left"`
+	Right *closer `inject:"right"`
+}
+
+func TestWarmUp(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test warm up",
+		AllowMissingDependencies: true,
+		WarmUpConcurrency:        2,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	// left and right can only be built if they are built at the same time
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	var built atomic.Int32
+	for _, name := range []string{"left", "right"} {
+		err = RegisterInstanceFunc[*closer](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+			built.Add(1)
+			arrived.Done()
+
+			done := make(chan struct{})
+			go func() {
+				arrived.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				return &closer{name: name}, nil
+			case <-time.After(time.Second):
+				return nil, fmt.Errorf("%s was not built in parallel", name)
+			}
+		}, name)
+		assert.Nil(t, err, "error registering closer")
+	}
+
+	err = RegisterSingleton[pair, pair](container)
+	assert.Nil(t, err, "error registering pair")
+
+	err = container.WarmUp(context.Background())
+	assert.Nil(t, err, "error warming up container")
+	assert.Equal(t, int32(2), built.Load())
+
+	// the singletons are not built again
+	p, err := GetFromContainer[*pair]("test warm up")
+	assert.Nil(t, err, "error getting pair")
+	assert.Equal(t, "left", p.Left.name)
+	assert.Equal(t, "right", p.Right.name)
+	assert.Equal(t, int32(2), built.Load())
+}
+
+func TestWarmUpConcurrency(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test warm up concurrency",
+		AllowMissingDependencies: true,
+		WarmUpConcurrency:        1,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	var running, maxRunning atomic.Int32
+	for _, name := range []string{"a", "b", "c", "d"} {
+		err = RegisterInstanceFunc[*closer](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			return &closer{name: name}, nil
+		}, name)
+		assert.Nil(t, err, "error registering closer")
+	}
+
+	err = container.WarmUp(context.Background())
+	assert.Nil(t, err, "error warming up container")
+	assert.Equal(t, int32(1), maxRunning.Load(), "more singletons were built at once than allowed")
+}
+
+func TestWarmUpErrors(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test warm up errors",
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	var attempts atomic.Int32
+	err = RegisterInstanceFunc[*closer](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		attempts.Add(1)
+		return nil, fmt.Errorf("connection refused")
+	}, "left")
+	assert.Nil(t, err, "error registering left")
+
+	err = RegisterInstanceFunc[*closer](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		return nil, fmt.Errorf("bad config")
+	}, "other")
+	assert.Nil(t, err, "error registering other")
+
+	registerClosers(t, container, lifecycles.Singleton, &eventLog{}, "right")
+
+	err = RegisterSingleton[pair, pair](container)
+	assert.Nil(t, err, "error registering pair")
+
+	err = container.WarmUp(context.Background())
+	assert.ErrorContains(t, err, "failed to build dependency 'left': failed to create dependency 'left'. Dependency chain: left: connection refused")
+	assert.ErrorContains(t, err, "failed to build dependency 'other': failed to create dependency 'other'. Dependency chain: other: bad config")
+	assert.ErrorContains(t, err, "failed to build dependency 'github.com/Gobusters/ectoinject.pair': dependency 'left' failed to build")
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3, "unexpected number of errors")
+	assert.Equal(t, int32(1), attempts.Load(), "a failed singleton should not be built again by its dependents")
+
+	// the singletons that do not depend on a failed singleton are built
+	_, right, err := container.Get(context.Background(), "right")
+	assert.Nil(t, err, "error getting right")
+	assert.Equal(t, "right", right.(*closer).name)
+}
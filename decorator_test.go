@@ -0,0 +1,149 @@
+package ectoinject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type quoteStore interface {
+	Quote() string
+}
+
+type baseQuoteStore struct {
+	closed bool
+}
+
+func (s *baseQuoteStore) Quote() string {
+	return "base"
+}
+
+func (s *baseQuoteStore) Close() error {
+	s.closed = true
+	return nil
+}
+
+type wrappedQuoteStore struct {
+	inner quoteStore
+	layer string
+}
+
+func (s *wrappedQuoteStore) Quote() string {
+	return s.layer + "(" + s.inner.Quote() + ")"
+}
+
+type quoteMetrics struct {
+	calls int
+}
+
+func TestRegisterDecorator(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test register decorator"})
+	assert.Nil(t, err, "error creating container")
+
+	base := &baseQuoteStore{}
+	metrics := &quoteMetrics{}
+	assert.Nil(t, RegisterProvider(container, lifecycles.Singleton, func() quoteStore { return base }))
+	assert.Nil(t, RegisterInstance[*quoteMetrics](container, metrics))
+
+	decorated := 0
+	assert.Nil(t, RegisterDecorator(container, func(ctx context.Context, inner quoteStore) (quoteStore, error) {
+		decorated++
+		return &wrappedQuoteStore{inner: inner, layer: "cache"}, nil
+	}))
+	assert.Nil(t, RegisterDecoratorFunc[quoteStore](container, func(inner quoteStore, metrics *quoteMetrics) quoteStore {
+		metrics.calls++
+		return &wrappedQuoteStore{inner: inner, layer: "metrics"}
+	}))
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test register decorator")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, store, err := GetContext[quoteStore](ctx)
+	assert.Nil(t, err, "error getting store")
+	assert.Equal(t, "metrics(cache(base))", store.Quote(), "decorators should run in registration order")
+
+	_, again, err := GetContext[quoteStore](ctx)
+	assert.Nil(t, err, "error getting store")
+	assert.Same(t, store, again)
+	assert.Equal(t, 1, decorated, "singletons should be decorated once")
+	assert.Equal(t, 1, metrics.calls, "decorators should get their dependencies from the container")
+
+	// the instance built by the container is disposed, not the decorator
+	assert.Nil(t, container.Close(context.Background()))
+	assert.True(t, base.closed, "the decorated instance should be disposed")
+}
+
+func TestDecoratorErrors(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test decorator errors"})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterDecoratorFunc[quoteStore](container, func(inner *baseQuoteStore) quoteStore { return inner })
+	assert.ErrorContains(t, err, "must have an arg of type 'github.com/Gobusters/ectoinject.quoteStore'")
+
+	err = RegisterDecoratorFunc[quoteStore](container, func(inner quoteStore) string { return "" })
+	assert.ErrorContains(t, err, "must return 'github.com/Gobusters/ectoinject.quoteStore' and optionally an error")
+
+	assert.Nil(t, RegisterProvider(container, lifecycles.Transient, func() quoteStore { return &baseQuoteStore{} }))
+	assert.Nil(t, RegisterDecoratorFunc[quoteStore](container, func(inner quoteStore, metrics *quoteMetrics) quoteStore { return inner }))
+
+	// the dependencies of decorators are part of the graph
+	assert.ErrorIs(t, container.Validate(), ErrNotFound)
+
+	_, err = GetFromContainer[quoteStore]("test decorator errors")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Nil(t, RegisterProvider(container, lifecycles.Transient, func() *quoteMetrics { return &quoteMetrics{} }))
+	assert.Nil(t, RegisterDecorator(container, func(ctx context.Context, inner quoteStore) (quoteStore, error) {
+		return nil, errors.New("cache is down")
+	}))
+
+	_, err = GetFromContainer[quoteStore]("test decorator errors")
+	var constructorErr *ConstructorError
+	assert.ErrorAs(t, err, &constructorErr)
+	assert.ErrorContains(t, err, "cache is down")
+}
+
+type greetingService struct {
+	greeting string
+}
+
+type farewellService struct {
+	farewell string
+}
+
+func TestDecoratorMatchesPointerAndElement(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test decorator matches pointer and element"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterSingleton[greetingService, greetingService](container))
+	assert.Nil(t, RegisterProvider(container, lifecycles.Singleton, func() *farewellService { return &farewellService{farewell: "bye"} }))
+
+	// a pointer decorator for a value registration, and a value decorator for a pointer registration
+	assert.Nil(t, RegisterDecoratorFunc[*greetingService](container, func(inner *greetingService) *greetingService {
+		return &greetingService{greeting: inner.greeting + "hello"}
+	}))
+	assert.Nil(t, RegisterDecoratorFunc[farewellService](container, func(inner farewellService) farewellService {
+		return farewellService{farewell: inner.farewell + "!"}
+	}))
+
+	ctx, err := SetActiveContainer(context.Background(), "test decorator matches pointer and element")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, greeting, err := GetContext[*greetingService](ctx)
+	assert.Nil(t, err, "error getting greeting service")
+	assert.Equal(t, "hello", greeting.greeting)
+
+	ctx, farewell, err := GetContext[*farewellService](ctx)
+	assert.Nil(t, err, "error getting farewell service")
+	assert.Equal(t, "bye!", farewell.farewell)
+
+	_, again, err := GetContext[*farewellService](ctx)
+	assert.Nil(t, err, "error getting farewell service")
+	assert.Same(t, farewell, again, "the decorated singleton should be shared")
+}
@@ -5,17 +5,50 @@ import (
 	"reflect"
 )
 
-// Dependency is the interface for a dependency. Provides methods for getting and setting the value of the dependency
+// Dependency is the interface for a dependency registration. It describes how an instance of the dependency is created. Instances are cached by the container or the scope, never by the registration
 type Dependency interface {
-	SetValue(reflect.Value) error                        // SetValue sets the value of the dependency
-	GetValue() reflect.Value                             // GetValue gets the value of the dependency
-	GetInstance() (any, error)                           // GetInstance gets the instance of the dependency
-	HasValue() bool                                      // HasValue checks if the dependency has a value
 	HasConstructor() bool                                // HasConstructor checks if the dependency has a constructor func
+	HasProvider() bool                                   // HasProvider checks if the dependency has a provider func
+	GetProvider() reflect.Value                          // GetProvider gets the provider func of the dependency
+	GetProviderName() string                             // GetProviderName gets the name of the provider func of the dependency
 	GetConstructor() reflect.Method                      // GetConstructor gets the constructor func of the dependency
 	GetInstanceFunc() func(context.Context) (any, error) // GetInstanceFunc returns the custom instance func of the dependency
 	GetDependencyType() reflect.Type                     // GetDependencyType returns the type of the dependency
 	GetName() string                                     // GetName returns the name of the dependency
+	IsNamed() bool                                       // IsNamed checks if the dependency was registered with a name. Unnamed dependencies are named after their type
 	GetLifecycle() string                                // GetLifecycle returns the lifecycle of the dependency
 	GetDependencyValueType() reflect.Type                // GetDependencyValueType gets the type of the dependency value
+	IsInstance() bool                                    // IsInstance checks if the dependency is an instance provided by the user. Instances are never disposed by the container
+	GetProduct() Dependency                              // GetProduct gets the dependency created by the factory func of the dependency. Returns nil if the dependency is not a factory
+	GetResultOf() (Dependency, []int)                    // GetResultOf gets the dependency whose result struct provides this dependency and the index of its field. Returns nil if the dependency is not provided by a result struct
+}
+
+// Decorator wraps the instances of every registration of a type. Decorators of a type run in registration order each time the container builds an instance of the type
+type Decorator interface {
+	GetDecoratedType() reflect.Type // GetDecoratedType gets the type of the instances the decorator wraps
+	GetFunc() reflect.Value         // GetFunc gets the decorator func. The func returns the decorated instance and optionally an error
+	GetFuncName() string            // GetFuncName gets the name of the decorator func
+	GetInnerIndex() int             // GetInnerIndex gets the index of the arg the instance is passed to. Every other arg is resolved from the container
+}
+
+// Key identifies a registration by type and name. It is implemented by ectoinject.Key
+type Key interface {
+	Type() reflect.Type // Type returns the type of the dependency
+	Name() string       // Name returns the name of the dependency. Empty for unnamed dependencies
+}
+
+// In marks a struct as a parameter object. When a constructor has an arg of a struct type that embeds In, each field of the struct is injected instead of the struct itself.
+// Fields are matched by their type or by the name in their inject tag. Fields tagged with `inject:",optional"` are left empty when their dependency is not registered.
+// context.Context fields get the context of the resolution
+type In struct{}
+
+// Out marks a struct as a result object. When a dependency is of a struct type that embeds Out, such as the result of its constructor, each field of the struct is registered as its own dependency.
+// Fields are named with their inject tag. Every field shares the one call that creates the struct and the lifecycle of the dependency
+type Out struct{}
+
+// Deferred is implemented by the pointer of wrappers that resolve a dependency when they are used instead of when they are injected, such as ectoinject.Lazy and ectoinject.Provider.
+// The container injects the wrapper after setting its resolver
+type Deferred interface {
+	DependencyType() reflect.Type            // DependencyType gets the type of the wrapped dependency
+	SetResolver(resolve func() (any, error)) // SetResolver sets the func used to resolve the wrapped dependency. The func resolves the dependency with the container and scope the wrapper was injected from
 }
@@ -2,16 +2,45 @@ package ectocontainer
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/Gobusters/ectoinject/dependency"
 )
 
 // DIContainer is the interface for the container
 type DIContainer interface {
-	Get(ctx context.Context, name string) (context.Context, any, error) // Gets a dependency from the container
-	GetConstructorFuncName() string                                     // Gets the name of the constructor function
-	AddDependency(dep dependency.Dependency)                            // Adds a dependency to the container
-	GetContainerID() string                                             // Gets the id of the container
+	Get(ctx context.Context, name string) (context.Context, any, error)                       // Gets a dependency from the container by name
+	GetByType(ctx context.Context, t reflect.Type, name string) (context.Context, any, error) // Gets a dependency from the container by type and optional name
+	GetAll(ctx context.Context, t reflect.Type) (context.Context, []any, error)               // Gets every registration of the type in collection order
+	Inject(ctx context.Context, target any) (context.Context, error)                          // Sets the dependencies of a struct created outside the container
+	Invoke(ctx context.Context, fn any) (context.Context, []any, error)                       // Calls the func with its args resolved from the container
+	SetPriority(t reflect.Type, name string, priority int) error                              // Sets the priority of a registration in collections
+	GetConstructorFuncName() string                                                           // Gets the name of the constructor function
+	AddDependency(dep dependency.Dependency)                                                  // Adds a dependency to the container
+	AddDecorator(dec dependency.Decorator)                                                    // Adds a decorator for the instances of every registration of its type
+	GetContainerID() string                                                                   // Gets the id of the container
+	Validate(keys ...dependency.Key) error                                                    // Checks every registration and the provided keys for problems without building any instance
+	WarmUp(ctx context.Context) error                                                         // Builds every singleton, building independent singletons in parallel
+	Start(ctx context.Context) error                                                          // Builds every singleton and starts them in dependency order
+	Stop(ctx context.Context) error                                                           // Stops the started singletons in reverse order
+	Close(ctx context.Context) error                                                          // Disposes the instances created by the container and closes it
+}
+
+// Starter is implemented by singletons that need to be started by the container. Start is called after the dependencies of the singleton have started
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by singletons that need to be stopped by the container. Stop is called before the dependencies of the singleton are stopped
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// DIScope is the interface for a scope. Scoped dependencies are cached by the scope until it is closed
+type DIScope interface {
+	Close(ctx context.Context) error // Ends the scope and disposes its instances in reverse creation order
+	IsClosed() bool                  // Checks if the scope has been closed
+	Len() int                        // Gets the number of instances cached by the scope
 }
 
 // DIContainerLoggerConfig is the configuration for the logger used by the container
@@ -34,4 +63,10 @@ type DIContainerConfig struct {
 	LoggerConfig             *DIContainerLoggerConfig // The logger configuration to use
 	ConstructorFuncName      string                   // The name of the constructor to use
 	InjectTagName            string                   // The name of the inject tag to use
+	InitFuncName             string                   // The name of the method called on field injected dependencies once their fields are injected. Defaults to "Init" and "-" disables the init func
+	InjectMethodPrefix       string                   // The prefix of the methods called with their args resolved from the container once a dependency is built. Method injection is disabled when empty
+	DisposeFuncName          string                   // The name of the method used to dispose instances when their scope or the container is closed. Instances that implement io.Closer are disposed with Close
+	StartFuncName            string                   // The name of the method used to start singletons. Singletons that implement Starter are started with Start
+	StopFuncName             string                   // The name of the method used to stop singletons. Singletons that implement Stopper are stopped with Stop
+	WarmUpConcurrency        int                      // The maximum number of singletons built in parallel by WarmUp. Defaults to GOMAXPROCS
 }
@@ -0,0 +1,74 @@
+package ectoerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is matched by errors.Is when a dependency is not registered
+var ErrNotFound = errors.New("dependency not found")
+
+// NotFoundError is returned when a dependency is not registered. It matches ErrNotFound
+type NotFoundError struct {
+	Dependency string   // The name of the dependency that is not registered
+	Chain      []string // The names of the dependencies being resolved from the root dependency down to the dependency that needs it. Empty when the dependency was requested directly
+	Func       string   // The name of the constructor func that needs the dependency. Empty when the dependency is needed by a field
+}
+
+func (e *NotFoundError) Error() string {
+	if len(e.Chain) == 0 {
+		return fmt.Sprintf("dependency for %s not found", e.Dependency)
+	}
+
+	parent := e.Chain[len(e.Chain)-1]
+	if e.Func != "" {
+		return fmt.Sprintf("dependency '%s' has unregistered dependency '%s' in '%s' func", parent, e.Dependency, e.Func)
+	}
+
+	return fmt.Sprintf("%s has a dependency on %s, but it is not registered", parent, e.Dependency)
+}
+
+// Is matches ErrNotFound
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// CircularDependencyError is returned when a dependency depends on itself
+type CircularDependencyError struct {
+	Dependency string   // The name of the dependency that depends on itself
+	Chain      []string // The names of the dependencies being resolved from the root dependency down to the repeated dependency
+}
+
+func (e *CircularDependencyError) Error() string {
+	return fmt.Sprintf("circular dependency detected for '%s'. Dependency chain: %s", e.Dependency, strings.Join(e.Chain, " -> "))
+}
+
+// CaptiveDependencyError is returned when a dependency would be held captive by a dependency with a longer lifecycle. For example a singleton that depends on a transient
+type CaptiveDependencyError struct {
+	Parent          string   // The name of the dependency with the longer lifecycle
+	ParentLifecycle string   // The lifecycle of the parent
+	Child           string   // The name of the dependency that would be held captive
+	ChildLifecycle  string   // The lifecycle of the child
+	Chain           []string // The names of the dependencies being resolved from the root dependency down to the child
+}
+
+func (e *CaptiveDependencyError) Error() string {
+	return fmt.Sprintf("captive dependency error: %s is a %s but has a %s dependency %s", e.Parent, e.ParentLifecycle, e.ChildLifecycle, e.Child)
+}
+
+// ConstructorError is returned when the constructor or instance func of a dependency fails
+type ConstructorError struct {
+	Dependency string   // The name of the dependency that failed to be created
+	Chain      []string // The names of the dependencies being resolved from the root dependency down to the dependency
+	Cause      error    // The error returned by the constructor or instance func
+}
+
+func (e *ConstructorError) Error() string {
+	return fmt.Sprintf("failed to create dependency '%s'. Dependency chain: %s: %v", e.Dependency, strings.Join(e.Chain, " -> "), e.Cause)
+}
+
+// Unwrap gets the error returned by the constructor or instance func
+func (e *ConstructorError) Unwrap() error {
+	return e.Cause
+}
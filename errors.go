@@ -0,0 +1,18 @@
+package ectoinject
+
+import "github.com/Gobusters/ectoinject/ectoerrors"
+
+// ErrNotFound is matched by errors.Is when a dependency is not registered
+var ErrNotFound = ectoerrors.ErrNotFound
+
+// NotFoundError is returned when a dependency is not registered. It matches ErrNotFound
+type NotFoundError = ectoerrors.NotFoundError
+
+// CircularDependencyError is returned when a dependency depends on itself
+type CircularDependencyError = ectoerrors.CircularDependencyError
+
+// CaptiveDependencyError is returned when a dependency would be held captive by a dependency with a longer lifecycle
+type CaptiveDependencyError = ectoerrors.CaptiveDependencyError
+
+// ConstructorError is returned when the constructor or instance func of a dependency fails
+type ConstructorError = ectoerrors.ConstructorError
@@ -0,0 +1,175 @@
+package ectoinject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type kitchen struct {
+	Cook Person `inject:"chef"`
+}
+
+type restaurant struct {
+	Kitchen *kitchen `inject:""`
+}
+
+// sousChef depends on the kitchen that depends on it
+type sousChef struct {
+	Kitchen *kitchen `inject:""`
+}
+
+func (c *sousChef) Speak() string {
+	return "yes chef"
+}
+
+func (c *sousChef) Count() int {
+	return 0
+}
+
+func TestNotFoundError(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test not found error",
+		AllowCaptiveDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[restaurant, restaurant](container)
+	assert.Nil(t, err, "error registering restaurant")
+
+	err = RegisterSingleton[kitchen, kitchen](container)
+	assert.Nil(t, err, "error registering kitchen")
+
+	ctx, err := SetActiveContainer(context.Background(), "test not found error")
+	assert.Nil(t, err, "error setting active container")
+
+	_, _, err = GetNamedDependency[Person](ctx, "waiter")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = GetContext[*restaurant](ctx)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var notFound *NotFoundError
+	if assert.ErrorAs(t, err, &notFound) {
+		assert.Equal(t, "chef", notFound.Dependency)
+		assert.Equal(t, []string{"github.com/Gobusters/ectoinject.restaurant", "github.com/Gobusters/ectoinject.kitchen"}, notFound.Chain)
+	}
+
+	assert.ErrorIs(t, container.Validate(), ErrNotFound)
+}
+
+func TestCircularDependencyError(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test circular dependency error",
+		AllowCaptiveDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[restaurant, restaurant](container)
+	assert.Nil(t, err, "error registering restaurant")
+
+	err = RegisterSingleton[kitchen, kitchen](container)
+	assert.Nil(t, err, "error registering kitchen")
+
+	err = RegisterSingleton[Person, sousChef](container, "chef")
+	assert.Nil(t, err, "error registering chef")
+
+	_, _, err = container.Get(context.Background(), "github.com/Gobusters/ectoinject.restaurant")
+	var circular *CircularDependencyError
+	if assert.ErrorAs(t, err, &circular) {
+		assert.Equal(t, "github.com/Gobusters/ectoinject.kitchen", circular.Dependency)
+		assert.Equal(t, []string{"github.com/Gobusters/ectoinject.restaurant", "github.com/Gobusters/ectoinject.kitchen", "chef", "github.com/Gobusters/ectoinject.kitchen"}, circular.Chain)
+	}
+
+	assert.ErrorAs(t, container.Validate(), &circular)
+}
+
+func TestCaptiveDependencyError(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test captive dependency error",
+		AllowCaptiveDependencies: false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[restaurant, restaurant](container)
+	assert.Nil(t, err, "error registering restaurant")
+
+	err = RegisterSingleton[kitchen, kitchen](container)
+	assert.Nil(t, err, "error registering kitchen")
+
+	err = RegisterTransient[Person, Human](container, "chef")
+	assert.Nil(t, err, "error registering chef")
+
+	_, _, err = container.Get(context.Background(), "github.com/Gobusters/ectoinject.restaurant")
+	var captive *CaptiveDependencyError
+	if assert.ErrorAs(t, err, &captive) {
+		assert.Equal(t, "github.com/Gobusters/ectoinject.restaurant", captive.Parent)
+		assert.Equal(t, lifecycles.Singleton, captive.ParentLifecycle)
+		assert.Equal(t, "chef", captive.Child)
+		assert.Equal(t, lifecycles.Transient, captive.ChildLifecycle)
+		assert.Equal(t, []string{"github.com/Gobusters/ectoinject.restaurant", "github.com/Gobusters/ectoinject.kitchen", "chef"}, captive.Chain)
+	}
+
+	assert.ErrorAs(t, container.Validate(), &captive)
+}
+
+func TestConstructorError(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test constructor error",
+		AllowCaptiveDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[restaurant, restaurant](container)
+	assert.Nil(t, err, "error registering restaurant")
+
+	err = RegisterSingleton[kitchen, kitchen](container)
+	assert.Nil(t, err, "error registering kitchen")
+
+	errUnavailable := errors.New("chef is unavailable")
+	err = RegisterInstanceFunc[Person](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		return nil, errUnavailable
+	}, "chef")
+	assert.Nil(t, err, "error registering chef")
+
+	_, _, err = container.Get(context.Background(), "github.com/Gobusters/ectoinject.restaurant")
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, "failed to create dependency 'chef'. Dependency chain: github.com/Gobusters/ectoinject.restaurant -> github.com/Gobusters/ectoinject.kitchen -> chef: chef is unavailable", err.Error())
+
+	var constructorErr *ConstructorError
+	if assert.ErrorAs(t, err, &constructorErr) {
+		assert.Equal(t, "chef", constructorErr.Dependency)
+		assert.Equal(t, errUnavailable, constructorErr.Cause)
+	}
+
+	// errors returned by constructors are wrapped too
+	err = RegisterSingleton[Animal, brokenAnimal](container)
+	assert.Nil(t, err, "error registering animal")
+
+	_, _, err = container.Get(context.Background(), "github.com/Gobusters/ectoinject.Animal")
+	assert.ErrorAs(t, err, &constructorErr)
+	assert.Equal(t, "github.com/Gobusters/ectoinject.Animal", constructorErr.Dependency)
+}
+
+type brokenAnimal struct{}
+
+func (a *brokenAnimal) Speak() string {
+	return ""
+}
+
+func (a *brokenAnimal) Constructor() (*brokenAnimal, error) {
+	return nil, fmt.Errorf("broken")
+}
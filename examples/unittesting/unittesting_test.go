@@ -2,6 +2,7 @@ package unittesting
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/Gobusters/ectoinject"
@@ -17,6 +18,26 @@ func (m *ContainerMock) Get(ctx context.Context, name string) (context.Context,
 	return ctx, m.FooMock, nil
 }
 
+func (m *ContainerMock) GetByType(ctx context.Context, t reflect.Type, name string) (context.Context, any, error) {
+	return ctx, m.FooMock, nil
+}
+
+func (m *ContainerMock) GetAll(ctx context.Context, t reflect.Type) (context.Context, []any, error) {
+	return ctx, []any{m.FooMock}, nil
+}
+
+func (m *ContainerMock) Inject(ctx context.Context, target any) (context.Context, error) {
+	return ctx, nil
+}
+
+func (m *ContainerMock) Invoke(ctx context.Context, fn any) (context.Context, []any, error) {
+	return ctx, nil, nil
+}
+
+func (m *ContainerMock) SetPriority(t reflect.Type, name string, priority int) error {
+	return nil
+}
+
 func (m *ContainerMock) GetConstructorFuncName() string {
 	return ""
 }
@@ -25,10 +46,34 @@ func (m *ContainerMock) AddDependency(dep dependency.Dependency) {
 
 }
 
+func (m *ContainerMock) AddDecorator(dec dependency.Decorator) {
+
+}
+
 func (m *ContainerMock) GetContainerID() string {
 	return m.ID
 }
 
+func (m *ContainerMock) Validate(keys ...dependency.Key) error {
+	return nil
+}
+
+func (m *ContainerMock) WarmUp(ctx context.Context) error {
+	return nil
+}
+
+func (m *ContainerMock) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *ContainerMock) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *ContainerMock) Close(ctx context.Context) error {
+	return nil
+}
+
 type FooMock struct {
 }
 
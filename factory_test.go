@@ -0,0 +1,152 @@
+package ectoinject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type reportJob struct {
+	tenantID string
+	pages    int
+	owner    Person
+}
+
+func (j *reportJob) Constructor(owner Person, tenantID string, pages int) (*reportJob, error) {
+	if pages < 0 {
+		return nil, errors.New("pages cannot be negative")
+	}
+	return &reportJob{tenantID: tenantID, pages: pages, owner: owner}, nil
+}
+
+type jobFactory func(tenantID string, pages int) (*reportJob, error)
+
+type exportJob struct {
+	TenantID string `inject:",assisted"`
+	Owner    Person `inject:""`
+}
+
+type exportFactory func(tenantID string) (*exportJob, error)
+
+type scheduler struct {
+	NewJob    jobFactory    `inject:""`
+	NewExport exportFactory `inject:""`
+}
+
+func TestFactory(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test factory"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterInstance[Person](container, &Human{Name: "peter"}))
+	assert.Nil(t, RegisterFactory[jobFactory, reportJob](container, lifecycles.Singleton))
+	assert.Nil(t, RegisterFactory[exportFactory, exportJob](container, lifecycles.Singleton))
+	assert.Nil(t, RegisterSingleton[scheduler, scheduler](container))
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test factory")
+	assert.Nil(t, err, "error setting active container")
+
+	// the singleton factories capture the root scope, so they outlive the scope the scheduler was built in
+	ctx, requestScope := NewScope(ctx)
+	_, s, err := GetContext[*scheduler](ctx)
+	assert.Nil(t, err, "error getting scheduler")
+	assert.Nil(t, requestScope.Close(context.Background()))
+
+	job, err := s.NewJob("acme", 3)
+	assert.Nil(t, err, "error creating job")
+	assert.Equal(t, "acme", job.tenantID)
+	assert.Equal(t, 3, job.pages)
+	assert.Equal(t, "peter", job.owner.(*Human).Name)
+
+	other, err := s.NewJob("globex", 1)
+	assert.Nil(t, err, "error creating job")
+	assert.NotSame(t, job, other, "factories should create a new instance on every call")
+	assert.Equal(t, "globex", other.tenantID)
+
+	_, err = s.NewJob("acme", -1)
+	var constructorErr *ConstructorError
+	assert.ErrorAs(t, err, &constructorErr)
+
+	export, err := s.NewExport("initech")
+	assert.Nil(t, err, "error creating export")
+	assert.Equal(t, "initech", export.TenantID)
+	assert.Equal(t, "peter", export.Owner.(*Human).Name)
+}
+
+func TestFactoryProductIsDecorated(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test factory product is decorated"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterInstance[Person](container, &Human{Name: "peter"}))
+	assert.Nil(t, RegisterFactory[jobFactory, reportJob](container, lifecycles.Singleton))
+	assert.Nil(t, RegisterFactory[exportFactory, exportJob](container, lifecycles.Singleton))
+	assert.Nil(t, RegisterDecoratorFunc[*reportJob](container, func(inner *reportJob) *reportJob {
+		return &reportJob{tenantID: "decorated " + inner.tenantID, pages: inner.pages, owner: inner.owner}
+	}))
+	assert.Nil(t, RegisterDecoratorFunc[*exportJob](container, func(inner *exportJob) *exportJob {
+		return &exportJob{TenantID: "decorated " + inner.TenantID, Owner: inner.Owner}
+	}))
+
+	ctx, err := SetActiveContainer(context.Background(), "test factory product is decorated")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, newJob, err := GetContext[jobFactory](ctx)
+	assert.Nil(t, err, "error getting job factory")
+
+	job, err := newJob("acme", 3)
+	assert.Nil(t, err, "error creating job")
+	assert.Equal(t, "decorated acme", job.tenantID)
+	assert.Equal(t, 3, job.pages)
+
+	_, newExport, err := GetContext[exportFactory](ctx)
+	assert.Nil(t, err, "error getting export factory")
+
+	export, err := newExport("initech")
+	assert.Nil(t, err, "error creating export")
+	assert.Equal(t, "decorated initech", export.TenantID)
+	assert.Equal(t, "peter", export.Owner.(*Human).Name)
+}
+
+type badJobFactory func(tenantID string, retries float64) (*reportJob, error)
+
+func TestFactoryErrors(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test factory errors"})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterFactory[string, reportJob](container, lifecycles.Singleton)
+	assert.EqualError(t, err, "factory 'string' must be a func type")
+
+	err = RegisterFactory[func() (*reportJob, string), reportJob](container, lifecycles.Singleton)
+	assert.ErrorContains(t, err, "must return the created type and an error")
+
+	err = RegisterFactory[func() *reportJob, reportJob](container, lifecycles.Singleton)
+	assert.ErrorContains(t, err, "must return the created type and an error")
+
+	err = RegisterFactory[jobFactory, Human](container, lifecycles.Singleton)
+	assert.ErrorContains(t, err, "is not assignable to")
+
+	err = RegisterFactory[badJobFactory, reportJob](container, lifecycles.Singleton)
+	assert.Nil(t, err, "error registering factory")
+
+	err = container.Validate()
+	assert.ErrorContains(t, err, "arg 1 of type 'float64' of the factory func cannot be passed to the constructor args or assisted fields")
+
+	_, factory, err := GetContext[badJobFactory](scopeContext(t, "test factory errors"))
+	assert.Nil(t, err, "error getting factory")
+
+	_, err = factory("acme", 1)
+	assert.ErrorContains(t, err, "cannot be passed to the constructor args or assisted fields")
+}
+
+func scopeContext(t *testing.T, containerID string) context.Context {
+	ctx, err := SetActiveContainer(context.Background(), containerID)
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, _ = NewScope(ctx)
+	return ctx
+}
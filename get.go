@@ -2,16 +2,19 @@ package ectoinject
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/Gobusters/ectoinject/ectocontainer"
 	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+	"github.com/Gobusters/ectoinject/internal/scope"
 )
 
 // GetFromContainer gets a dependency from the container. Returns the dependency and an error.
+// Scoped dependencies are cached by the root scope of the container. The cleanup funcs of transient dependencies are not run
 // T: The type of the dependency
 // containerID: The id of the container to get the dependency from
 func GetFromContainer[T any](containerID string) (T, error) {
-	ctx := context.Background()
+	ctx := scope.WithRootScope(context.Background())
 	ctx, err := SetActiveContainer(ctx, containerID)
 	if err != nil {
 		var zero T
@@ -23,25 +26,27 @@ func GetFromContainer[T any](containerID string) (T, error) {
 }
 
 // Get gets a dependency from the default container. Returns the dependency and an error.
+// Scoped dependencies are cached by the root scope of the container. The cleanup funcs of transient dependencies are not run
 // T: The type of the dependency
 func Get[T any]() (T, error) {
-	ctx := context.Background()
+	ctx := scope.WithRootScope(context.Background())
 	_, val, err := GetNamedDependency[T](ctx, "")
 	return val, err
 }
 
 // GetContext gets a dependency from the container. Returns a context with scoped dependencies caching, the dependency, and an error.
-// The context is used to provide scoped caching of dependencies.
+// The context is used to provide scoped caching of dependencies. If the context does not have a scope, a new scope is added to the returned context.
+// Use NewScope to control when the scope ends
 // T: The type of the dependency
 // ctx: The context to use. To use a non-default container, use SetActiveContainer
 func GetContext[T any](ctx context.Context) (context.Context, T, error) {
 	return GetNamedDependency[T](ctx, "")
 }
 
-// GetNamedDependency gets a dependency from the container by name
+// GetNamedDependency gets a dependency from the container by type and name
 // T: The type of the dependency
 // ctx: The context to use. To use a non-default container, use SetActiveContainer
-// name: The name of the dependency. Unnamed dependencies will use `{module}.{type}` as the name
+// name: (optional) The name of the dependency. Unnamed dependencies are matched by type
 func GetNamedDependency[T any](ctx context.Context, name string) (context.Context, T, error) {
 	var val T
 
@@ -50,23 +55,19 @@ func GetNamedDependency[T any](ctx context.Context, name string) (context.Contex
 		return ctx, val, err
 	}
 
-	reflectName := ectoreflect.GetIntefaceName[T]()
-	if name == "" {
-		name = reflectName
-	}
-
 	return GetDependency[T](ctx, activeContainer, name)
 }
 
-// GetDependency gets a dependency from the container by name
+// GetDependency gets a dependency from the container by type and name. The dependency registered for T with the name is used.
+// If there is none, the dependency registered for *T or the element type of T is used. Finally, the only dependency registered with the name is used
 // T: The type of the dependency
 // ctx: The context to use. To use a non-default container, use SetActiveContainer
 // container: The container to get the dependency from
-// name: The name of the dependency. Unnamed dependencies will use `{module}.{type}` as the name
+// name: (optional) The name of the dependency. Unnamed dependencies are matched by type
 func GetDependency[T any](ctx context.Context, container ectocontainer.DIContainer, name string) (context.Context, T, error) {
 	var val T
 
-	ctx, depValue, err := container.Get(ctx, name)
+	ctx, depValue, err := container.GetByType(ctx, reflect.TypeOf((*T)(nil)).Elem(), name)
 	if err != nil {
 		return ctx, val, err
 	}
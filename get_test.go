@@ -245,6 +245,45 @@ func TestGetScoped(t *testing.T) {
 	assert.Equal(t, 2, cityVal.House.Dad.Count())
 }
 
+func TestScopedInstancesAreNotShared(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test scoped instances are not shared",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		RequireInjectTag:         false,
+		AllowUnsafeDependencies:  false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterScoped[Person, Human](container)
+	assert.Nil(t, err, "error registering person dependency")
+
+	// two independent scopes
+	ctxA, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+	ctxB, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	ctxA, personA, err := GetContext[Person](ctxA)
+	assert.Nil(t, err, "error getting person in scope a")
+	assert.Equal(t, 1, personA.Count())
+
+	ctxB, personB, err := GetContext[Person](ctxB)
+	assert.Nil(t, err, "error getting person in scope b")
+	assert.Equal(t, 1, personB.Count())
+
+	_, personA, err = GetContext[Person](ctxA)
+	assert.Nil(t, err, "error getting person in scope a")
+	assert.Equal(t, 2, personA.Count())
+
+	_, personB, err = GetContext[Person](ctxB)
+	assert.Nil(t, err, "error getting person in scope b")
+	assert.Equal(t, 2, personB.Count())
+	assert.NotSame(t, personA, personB, "scopes share the same instance")
+}
+
 func TestGetTransient(t *testing.T) {
 	type house struct {
 		Dad *Human `inject:""`
@@ -472,6 +511,223 @@ func TestGetInstanceFunc(t *testing.T) {
 	assert.Equal(t, "Dave", houseVal.Dad.(*Human).Name)
 }
 
+func TestScopedInstanceFunc(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test scoped instance func",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		RequireInjectTag:         false,
+		AllowUnsafeDependencies:  false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	calls := 0
+	err = RegisterInstanceFunc[Person](container, lifecycles.Scoped, func(ctx context.Context) (any, error) {
+		calls++
+		return &Human{Name: "Dave"}, nil
+	})
+	assert.Nil(t, err, "error registering person dependency")
+
+	err = RegisterInstanceFunc[string](container, lifecycles.Scoped, func(ctx context.Context) (any, error) {
+		return "bar", nil
+	}, "foo")
+	assert.Nil(t, err, "error registering foo dependency")
+
+	ctx := context.Background()
+	ctx, err = SetActiveContainer(ctx, config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, person, err := GetContext[Person](ctx)
+	assert.Nil(t, err, "error getting person")
+	assert.Equal(t, "Dave", person.(*Human).Name)
+	assert.Equal(t, 1, person.Count())
+
+	ctx, person, err = GetContext[Person](ctx)
+	assert.Nil(t, err, "error getting person")
+	assert.Equal(t, 2, person.Count())
+	assert.Equal(t, 1, calls, "instance func was called more than once in the same scope")
+
+	_, foo, err := GetNamedDependency[string](ctx, "foo")
+	assert.Nil(t, err, "error getting foo")
+	assert.Equal(t, "bar", foo)
+}
+
+func TestScopedConstructor(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test scoped constructor",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		RequireInjectTag:         false,
+		AllowUnsafeDependencies:  false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterScoped[Animal, monkey](container, "monkey")
+	assert.Nil(t, err, "error registering monkey dependency")
+
+	err = RegisterSingleton[Animal, Dog](container)
+	assert.Nil(t, err, "error registering animal dependency")
+
+	ctx := context.Background()
+	ctx, err = SetActiveContainer(ctx, config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, monkeyVal, err := GetNamedDependency[Animal](ctx, "monkey")
+	assert.Nil(t, err, "error getting monkey")
+	assert.Equal(t, "woof", monkeyVal.Speak())
+
+	_, sameMonkey, err := GetNamedDependency[Animal](ctx, "monkey")
+	assert.Nil(t, err, "error getting monkey")
+	assert.Same(t, monkeyVal, sameMonkey, "scoped constructor was called more than once in the same scope")
+}
+
+type greeter struct {
+	Owner    Person `inject:""`
+	greeting string
+}
+
+func (g *greeter) Init(ctx context.Context) error {
+	if g.Owner == nil {
+		return fmt.Errorf("owner is required")
+	}
+
+	g.greeting = g.Owner.Speak() + " world"
+	return nil
+}
+
+func TestInitFunc(t *testing.T) {
+	type house struct {
+		Greeter *greeter `inject:""`
+	}
+
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test init func",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		RequireInjectTag:         false,
+		AllowUnsafeDependencies:  false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[Person, Human](container)
+	assert.Nil(t, err, "error registering person depenedency")
+
+	err = RegisterSingleton[greeter, greeter](container)
+	assert.Nil(t, err, "error registering greeter depenedency")
+
+	err = RegisterSingleton[house, house](container)
+	assert.Nil(t, err, "error registering house depenedency")
+
+	ctx := context.Background()
+	ctx, err = SetActiveContainer(ctx, config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, houseVal, err := GetContext[house](ctx)
+	assert.Nil(t, err, "error getting house")
+	assert.Equal(t, "hello world", houseVal.Greeter.greeting)
+}
+
+func TestInitFuncDisabled(t *testing.T) {
+	type house struct {
+		Greeter *greeter `inject:""`
+	}
+
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test init func disabled",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		InitFuncName:             "-",
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	// the init func would fail without an owner
+	err = RegisterSingleton[greeter, greeter](container)
+	assert.Nil(t, err, "error registering greeter depenedency")
+
+	err = RegisterSingleton[house, house](container)
+	assert.Nil(t, err, "error registering house depenedency")
+
+	ctx := context.Background()
+	ctx, err = SetActiveContainer(ctx, config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, houseVal, err := GetContext[house](ctx)
+	assert.Nil(t, err, "error getting house")
+	assert.Empty(t, houseVal.Greeter.greeting, "init func should not be called")
+}
+
+func TestInitFuncError(t *testing.T) {
+	type house struct {
+		Greeter *greeter `inject:""`
+	}
+
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test init func error",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		RequireInjectTag:         false,
+		AllowUnsafeDependencies:  false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[greeter, greeter](container)
+	assert.Nil(t, err, "error registering greeter depenedency")
+
+	err = RegisterSingleton[house, house](container)
+	assert.Nil(t, err, "error registering house depenedency")
+
+	ctx := context.Background()
+	ctx, err = SetActiveContainer(ctx, config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, _, err = GetContext[house](ctx)
+	assert.NotNil(t, err, "no error returned for failed init func")
+	assert.Equal(t, "failed to initialize dependency 'github.com/Gobusters/ectoinject.greeter'. Dependency chain: github.com/Gobusters/ectoinject.house -> github.com/Gobusters/ectoinject.greeter: 'Init' func failed: owner is required", err.Error())
+}
+
+type engine struct {
+	name string
+}
+
+// Init is unrelated to the container so it must not be called as the init func
+func (e *engine) Init(name string) {
+	e.name = name
+}
+
+func TestInitFuncWithOtherSignature(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test init func with other signature",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+		RequireInjectTag:         false,
+		AllowUnsafeDependencies:  false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[engine, engine](container)
+	assert.Nil(t, err, "error registering engine depenedency")
+
+	ctx := context.Background()
+	ctx, err = SetActiveContainer(ctx, config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, engineVal, err := GetContext[*engine](ctx)
+	assert.Nil(t, err, "error getting engine")
+	assert.Equal(t, "", engineVal.name)
+}
+
 func TestInstanceDependency(t *testing.T) {
 	type house struct {
 		Location string `inject:"foo"`
@@ -506,8 +762,9 @@ func TestInstanceDependency(t *testing.T) {
 
 func TestRequireInjectTag(t *testing.T) {
 	type house struct {
-		Dad Person
-		Mom Person `inject:"mom"`
+		Dad   Person
+		Uncle Person `inject:""`
+		Mom   Person `inject:"mom"`
 	}
 
 	config := ectocontainer.DIContainerConfig{
@@ -537,6 +794,7 @@ func TestRequireInjectTag(t *testing.T) {
 	_, houseInstance, err := GetContext[house](ctx)
 	assert.Nil(t, err, "error getting house instance")
 	assert.Nil(t, houseInstance.Dad, "dad dependency was set")
+	assert.Nil(t, houseInstance.Uncle, "uncle dependency was set")
 	assert.NotNil(t, houseInstance.Mom, "mom dependency was not set")
 }
 
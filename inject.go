@@ -0,0 +1,34 @@
+package ectoinject
+
+import (
+	"context"
+)
+
+// Inject sets the dependencies of a struct created outside the container, such as a test suite, an http handler or a CLI command.
+// The fields are injected with the same inject tag, unsafe field and missing dependency rules as a dependency built by the container.
+// Returns a context with the scoped dependencies created while injecting the struct and an error
+// ctx: The context to use. To use a non-default container, use SetActiveContainer
+// target: A pointer to the struct
+func Inject(ctx context.Context, target any) (context.Context, error) {
+	activeContainer, err := GetActiveContainer(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	return activeContainer.Inject(ctx, target)
+}
+
+// Invoke calls the func with its args resolved from the container, such as a migration, a CLI subcommand or a one-off job. The args follow the same rules as the args
+// of a constructor: context.Context args get the context, DIContainer args get the container and parameter objects have their fields injected.
+// Returns a context with the scoped dependencies created while resolving the args, the results of the func and an error.
+// If the last result of the func is an error, it is returned as the error instead of a result
+// ctx: The context to use. To use a non-default container, use SetActiveContainer
+// fn: The func to call, such as `func(db *DB, log Logger) error`
+func Invoke(ctx context.Context, fn any) (context.Context, []any, error) {
+	activeContainer, err := GetActiveContainer(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	return activeContainer.Invoke(ctx, fn)
+}
@@ -0,0 +1,93 @@
+package ectoinject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type handlerSuite struct {
+	Person  Person  `inject:""`
+	Ticket  *ticket `inject:""`
+	Skipped Animal  `inject:"-"`
+	private *ticket
+}
+
+func TestInject(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test inject"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterInstance[Person](container, &Human{Name: "peter"}))
+	assert.Nil(t, RegisterProvider(container, lifecycles.Scoped, func() *ticket { return &ticket{id: 7} }))
+
+	ctx, err := SetActiveContainer(context.Background(), "test inject")
+	assert.Nil(t, err, "error setting active container")
+
+	suite := &handlerSuite{}
+	ctx, err = Inject(ctx, suite)
+	assert.Nil(t, err, "error injecting suite")
+	assert.Equal(t, "peter", suite.Person.(*Human).Name)
+	assert.Equal(t, 7, suite.Ticket.id)
+	assert.Nil(t, suite.Skipped)
+	assert.Nil(t, suite.private, "private fields should not be injected unless unsafe dependencies are allowed")
+
+	// the returned context caches the scoped dependencies created by Inject
+	_, scoped, err := GetContext[*ticket](ctx)
+	assert.Nil(t, err, "error getting ticket")
+	assert.Same(t, suite.Ticket, scoped)
+
+	_, err = Inject(ctx, handlerSuite{})
+	assert.ErrorContains(t, err, "target must be a pointer to a struct but is ectoinject.handlerSuite")
+
+	type missing struct {
+		Animal Animal `inject:""`
+	}
+	_, err = Inject(ctx, &missing{})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+type migrationParams struct {
+	In
+	Person Person  `inject:""`
+	Ticket *ticket `inject:""`
+}
+
+func TestInvoke(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test invoke"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterInstance[Person](container, &Human{Name: "peter"}))
+	assert.Nil(t, RegisterProvider(container, lifecycles.Scoped, func() *ticket { return &ticket{id: 7} }))
+
+	ctx, err := SetActiveContainer(context.Background(), "test invoke")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, results, err := Invoke(ctx, func(ctx context.Context, di ectocontainer.DIContainer, params migrationParams) (string, int, error) {
+		assert.NotNil(t, ctx)
+		assert.Equal(t, "test invoke", di.GetContainerID())
+		return params.Person.(*Human).Name, params.Ticket.id, nil
+	})
+	assert.Nil(t, err, "error invoking func")
+	assert.Equal(t, []any{"peter", 7}, results)
+
+	// the returned context caches the scoped dependencies resolved for the func
+	_, scoped, err := GetContext[*ticket](ctx)
+	assert.Nil(t, err, "error getting ticket")
+	assert.Equal(t, 7, scoped.id)
+
+	_, results, err = Invoke(ctx, func(p Person) error {
+		return errors.New("migration failed")
+	})
+	assert.EqualError(t, err, "migration failed")
+	assert.Empty(t, results)
+
+	_, _, err = Invoke(ctx, func(a Animal) {})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = Invoke(ctx, "not a func")
+	assert.EqualError(t, err, "fn must be a func but is string")
+}
@@ -0,0 +1,104 @@
+package ectoinject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/stretchr/testify/assert"
+)
+
+type BaseDeps struct {
+	Person Person `inject:""`
+}
+
+type petDeps struct {
+	Pet Animal `inject:"rex"`
+}
+
+type vetDeps struct {
+	Pets *petDeps `inject:",inline"`
+}
+
+type clinic struct {
+	BaseDeps
+	Vet vetDeps `inject:",inline"`
+}
+
+// Settings is embedded but registered, so it is injected as a dependency
+type Settings struct {
+	Source string `inject:"-"`
+}
+
+type configuredClinic struct {
+	*Settings
+	BaseDeps
+}
+
+func TestInlineFields(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test inline fields"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterInstance[Person](container, &Human{Name: "peter"}))
+	assert.Nil(t, RegisterSingleton[Animal, Dog](container, "rex"))
+
+	assert.Nil(t, RegisterTransient[clinic, clinic](container))
+	assert.Nil(t, RegisterInstance[*Settings](container, &Settings{Source: "registered"}))
+	assert.Nil(t, RegisterTransient[configuredClinic, configuredClinic](container))
+	assert.Nil(t, container.Validate())
+
+	c, err := GetFromContainer[*clinic]("test inline fields")
+	assert.Nil(t, err, "error getting clinic")
+	assert.Equal(t, "peter", c.Person.(*Human).Name, "unregistered embedded structs should be injected field by field")
+	assert.NotNil(t, c.Vet.Pets, "nil pointers to inline structs should be created")
+	assert.IsType(t, &Dog{}, c.Vet.Pets.Pet)
+
+	configured, err := GetFromContainer[*configuredClinic]("test inline fields")
+	assert.Nil(t, err, "error getting configured clinic")
+	assert.Equal(t, "registered", configured.Source, "registered embedded structs should be injected as a dependency")
+	assert.Equal(t, "peter", configured.Person.(*Human).Name)
+
+	// structs created outside the container are walked the same way
+	target := &clinic{}
+	_, err = Inject(scopeContext(t, "test inline fields"), target)
+	assert.Nil(t, err, "error injecting clinic")
+	assert.IsType(t, &Dog{}, target.Vet.Pets.Pet)
+}
+
+type missingPetClinic struct {
+	Vet vetDeps `inject:",inline"`
+}
+
+type badInline struct {
+	Name string `inject:",inline"`
+}
+
+type selfInline struct {
+	Next *selfInline `inject:",inline"`
+}
+
+func TestInlineFieldErrors(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test inline field errors"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterTransient[missingPetClinic, missingPetClinic](container))
+	err = container.Validate()
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "rex")
+
+	_, err = GetFromContainer[*missingPetClinic]("test inline field errors")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Nil(t, RegisterTransient[badInline, badInline](container))
+	_, err = GetFromContainer[*badInline]("test inline field errors")
+	assert.ErrorContains(t, err, "field 'Name' has the inline option but type 'string' is not a struct")
+
+	assert.Nil(t, RegisterTransient[selfInline, selfInline](container))
+	_, err = GetFromContainer[*selfInline]("test inline field errors")
+	assert.ErrorContains(t, err, "field 'Next' cannot inline type '*ectoinject.selfInline' into itself")
+
+	_, err = Inject(context.Background(), &struct {
+		Vet vetDeps `inject:"vet,inline"`
+	}{})
+	assert.ErrorContains(t, err, "cannot have a name or other options with the inline option")
+}
@@ -0,0 +1,195 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	"github.com/Gobusters/ectoinject/internal/scope"
+	"github.com/Gobusters/ectoinject/lifecycles"
+)
+
+type contextKey string
+
+var contextResolutionKey = contextKey("ectoinject-resolution")
+
+// guards resolution.waitingOn across every container and scope
+var waitMu sync.Mutex
+
+// resolution is a single call to Get walking the dependency graph. It is used to detect resolutions that are waiting on each other
+type resolution struct {
+	waitingOn *scope.Instance // the instance the resolution is waiting on. Guarded by waitMu
+}
+
+// withResolution adds the resolution to the context passed to constructors and instance funcs. This allows nested calls to Get to be detected as part of the same resolution
+func withResolution(ctx context.Context, res *resolution) context.Context {
+	return context.WithValue(ctx, contextResolutionKey, res)
+}
+
+// resolutionFromContext gets the resolution from the context or starts a new one
+func resolutionFromContext(ctx context.Context) *resolution {
+	res, ok := ctx.Value(contextResolutionKey).(*resolution)
+	if !ok || res == nil {
+		res = &resolution{}
+	}
+
+	return res
+}
+
+// cacheKey is the key of a dependency instance cached by a scope. Scopes can be shared by containers so the key includes the container id
+type cacheKey struct {
+	containerID  string
+	registration registrationKey
+}
+
+// cacheKey gets the key of the instances of the dependency
+func (container *EctoContainer) cacheKey(dep dependency.Dependency) cacheKey {
+	return cacheKey{containerID: container.ID, registration: keyOf(dep)}
+}
+
+// createFunc creates a new instance of a dependency. Returns the func that disposes the instance in place of its dispose method, if any,
+// such as the cleanup func returned by the constructor or provider of the dependency
+type createFunc func(ctx context.Context) (context.Context, reflect.Value, func(context.Context) error, error)
+
+// getTransient creates a new instance of a transient dependency. A dispose func returned while creating the instance is tracked by the scope of the context.
+// If the context does not have a scope, a new scope is added to the context
+func (container *EctoContainer) getTransient(ctx context.Context, dep dependency.Dependency, create createFunc) (context.Context, reflect.Value, error) {
+	ctx, val, dispose, err := create(ctx)
+	if err != nil || dispose == nil {
+		return ctx, val, err
+	}
+
+	// transients resolved with Get or GetFromContainer would pile up in the root scope until the container is closed, so their cleanup is left to the caller
+	if _, ok := scope.FromContext(ctx); !ok && scope.UsesRootScope(ctx) {
+		container.logger.Warn(ctx, "transient dependency '%s' was resolved without a scope so its cleanup func will not be run. Use GetContext with a scope to run it", dep.GetName())
+		return ctx, val, nil
+	}
+
+	ctx, s := container.scopeFromContext(ctx)
+	err = s.Track(dep, dispose)
+	if err != nil {
+		return ctx, reflect.Value{}, fmt.Errorf("failed to get dependency '%s': %w", dep.GetName(), err)
+	}
+
+	return ctx, val, nil
+}
+
+// scopeFromContext gets the scope of the context. If the context does not have a scope, the root scope is used for contexts marked with scope.WithRootScope.
+// Otherwise a new scope is added to the context
+func (container *EctoContainer) scopeFromContext(ctx context.Context) (context.Context, *scope.Scope) {
+	s, ok := scope.FromContext(ctx)
+	if ok {
+		return ctx, s
+	}
+
+	if scope.UsesRootScope(ctx) {
+		return ctx, container.root
+	}
+
+	s = scope.New()
+	return scope.WithScope(ctx, s), s
+}
+
+// captureScope gets the context captured by funcs that resolve dependencies after the resolution has finished, such as factories, Lazy and Provider.
+// Singletons outlive every scope so funcs owned by a singleton capture the root scope of the container. Otherwise the scope of the context is captured.
+// The captured context is not cancelled with the context of the caller and does not take part in its resolution.
+// Returns the context to continue the resolution with and the captured context
+// owner: The dependency that holds the func
+func (container *EctoContainer) captureScope(ctx context.Context, owner dependency.Dependency) (context.Context, context.Context) {
+	var captured context.Context
+	if owner.GetLifecycle() == lifecycles.Singleton {
+		captured = scope.WithScope(ctx, container.root)
+	} else {
+		ctx, _ = container.scopeFromContext(ctx)
+		captured = ctx
+	}
+
+	return ctx, withResolution(context.WithoutCancel(captured), nil)
+}
+
+// getScoped gets the instance of a scoped dependency from the scope of the context. If the context does not have a scope, a new scope is added to the context
+func (container *EctoContainer) getScoped(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency, res *resolution, create createFunc) (context.Context, reflect.Value, error) {
+	ctx, s := container.scopeFromContext(ctx)
+	return container.getCached(ctx, s, dep, chain, res, create)
+}
+
+// getCached gets the instance of the dependency cached by the scope. If the scope does not have the instance, create is called exactly once no matter how many resolutions request the dependency
+func (container *EctoContainer) getCached(ctx context.Context, s *scope.Scope, dep dependency.Dependency, chain []dependency.Dependency, res *resolution, create createFunc) (context.Context, reflect.Value, error) {
+	name := dep.GetName()
+	key := container.cacheKey(dep)
+
+	instance, claimed, err := s.Claim(key, dep, res)
+	if err != nil {
+		return ctx, reflect.Value{}, fmt.Errorf("failed to get dependency '%s': %w", name, err)
+	}
+
+	if claimed {
+		// build the instance
+		ctx, val, dispose, err := create(ctx)
+		if dispose == nil && err == nil {
+			dispose = container.getDisposeFunc(dep, val)
+		}
+
+		err = s.Complete(key, instance, val, err, dispose)
+		return ctx, val, err
+	}
+
+	select {
+	case <-instance.Done():
+		val, err := instance.Value()
+		return ctx, val, err
+	default:
+	}
+
+	// the instance is being built by another resolution. Make sure that resolution is not waiting on this one
+	waitMu.Lock()
+	if waitCreatesCycle(res, instance) {
+		waitMu.Unlock()
+		// the chain already ends with the dependency
+		return ctx, reflect.Value{}, circularDependencyError(name, getChainNames(chain[:len(chain)-1]))
+	}
+	res.waitingOn = instance
+	waitMu.Unlock()
+
+	defer func() {
+		waitMu.Lock()
+		res.waitingOn = nil
+		waitMu.Unlock()
+	}()
+
+	select {
+	case <-instance.Done():
+		val, err := instance.Value()
+		return ctx, val, err
+	case <-ctx.Done():
+		return ctx, reflect.Value{}, fmt.Errorf("stopped waiting for dependency '%s': %w", name, ctx.Err())
+	}
+}
+
+// waitCreatesCycle checks if the resolution waiting on the instance would wait on itself. Must be called with waitMu held.
+// A resolution whose instance has been built is no longer waiting, even if it has not woken up to clear waitingOn yet
+func waitCreatesCycle(res *resolution, instance *scope.Instance) bool {
+	for owner, _ := instance.Owner.(*resolution); owner != nil; owner, _ = owner.waitingOn.Owner.(*resolution) {
+		if owner == res {
+			return true
+		}
+
+		if owner.waitingOn == nil || isDone(owner.waitingOn) {
+			return false
+		}
+	}
+
+	return false
+}
+
+// isDone checks if the instance has been built or its build has failed
+func isDone(instance *scope.Instance) bool {
+	select {
+	case <-instance.Done():
+		return true
+	default:
+		return false
+	}
+}
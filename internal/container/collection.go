@@ -0,0 +1,148 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	"github.com/Gobusters/ectoinject/ectoerrors"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// isCollection checks if the type is a slice or a map with string keys. A collection that is not registered itself is injected with every registration of its element type
+func isCollection(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice || (t.Kind() == reflect.Map && t.Key().Kind() == reflect.String)
+}
+
+// hasCollection checks if the type is a collection with at least one registration of its element type. A collection without registrations is a missing dependency
+func (container *EctoContainer) hasCollection(t reflect.Type) bool {
+	return isCollection(t) && len(container.getCollectionRegistrations(t.Elem())) > 0
+}
+
+// SetPriority sets the priority of a registration in collections. Registrations with a higher priority come first. Registrations with the same priority keep their registration order
+// t: The type of the dependency
+// name: (optional) The name of the dependency
+// priority: The priority of the registration. Defaults to 0
+func (container *EctoContainer) SetPriority(t reflect.Type, name string, priority int) error {
+	container.mu.Lock()
+	defer container.mu.Unlock()
+
+	key := registrationKey{Type: t, Name: name}
+	if _, ok := container.container[key]; !ok {
+		return &ectoerrors.NotFoundError{Dependency: key.String()}
+	}
+
+	container.priorities[key] = priority
+	return nil
+}
+
+// GetAll gets every registration of the type in collection order. Registrations of the pointer or element type of t are included
+// ctx: The context to use
+// t: The type of the dependencies
+func (container *EctoContainer) GetAll(ctx context.Context, t reflect.Type) (context.Context, []any, error) {
+	if container.closed.Load() {
+		return ctx, nil, fmt.Errorf("container '%s' is closed", container.ID)
+	}
+
+	var instances []any
+	for _, dep := range container.getCollectionRegistrations(t) {
+		var instance any
+		var err error
+		ctx, instance, err = container.resolve(ctx, dep)
+		if err != nil {
+			return ctx, nil, err
+		}
+
+		val, err := ectoreflect.CastType(t, instance)
+		if err != nil {
+			return ctx, nil, fmt.Errorf("failed to cast dependency '%s' to type '%s': %w", dep.GetName(), t, err)
+		}
+
+		instances = append(instances, val.Interface())
+	}
+
+	return ctx, instances, nil
+}
+
+// getCollectionRegistrations gets every registration of the element type in collection order. Registrations of the pointer or element type are included
+func (container *EctoContainer) getCollectionRegistrations(elemType reflect.Type) []dependency.Dependency {
+	container.mu.RLock()
+	defer container.mu.RUnlock()
+
+	other := variantOf(elemType)
+	var keys []registrationKey
+	for _, key := range container.order {
+		if key.Type == elemType || (other != nil && key.Type == other) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return container.priorities[keys[i]] > container.priorities[keys[j]]
+	})
+
+	deps := make([]dependency.Dependency, len(keys))
+	for i, key := range keys {
+		deps[i] = container.container[key]
+	}
+
+	return deps
+}
+
+// getCollection builds a slice with every registration of its element type, or a map with every registration of its element type keyed by registration name
+// t: The type of the collection
+func (container *EctoContainer) getCollection(ctx context.Context, dep dependency.Dependency, t reflect.Type, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, error) {
+	elemType := t.Elem()
+	deps := container.getCollectionRegistrations(elemType)
+
+	var collection reflect.Value
+	if t.Kind() == reflect.Map {
+		collection = reflect.MakeMapWithSize(t, len(deps))
+	} else {
+		collection = reflect.MakeSlice(t, 0, len(deps))
+	}
+
+	for _, childDep := range deps {
+		var childVal reflect.Value
+		var err error
+		ctx, childVal, err = container.getDependency(ctx, childDep, chain, res)
+		if err != nil {
+			return ctx, reflect.Value{}, err
+		}
+
+		if !childVal.IsValid() {
+			continue // nothing to add
+		}
+
+		val, err := ectoreflect.CastType(elemType, ectoreflect.GetPointerOfValue(childVal))
+		if err != nil {
+			return ctx, reflect.Value{}, fmt.Errorf("failed to add dependency '%s' to collection of dependency '%s': %w", childDep.GetName(), dep.GetName(), err)
+		}
+
+		if t.Kind() == reflect.Map {
+			collection.SetMapIndex(reflect.ValueOf(childDep.GetName()).Convert(t.Key()), val)
+		} else {
+			collection = reflect.Append(collection, val)
+		}
+	}
+
+	return ctx, collection, nil
+}
+
+// validateCollection checks that every registration of the element type of the collection can be added to it. Returns the registrations and the problems found
+// t: The type of the collection
+// target: The description of the field or arg the collection is injected into, used in errors
+func (container *EctoContainer) validateCollection(dep dependency.Dependency, t reflect.Type, target string) ([]dependency.Dependency, []error) {
+	deps := container.getCollectionRegistrations(t.Elem())
+
+	var errs []error
+	for _, childDep := range deps {
+		if !ectoreflect.CanCastType(t.Elem(), getInstanceType(childDep)) {
+			errs = append(errs, fmt.Errorf("%s on dependency '%s' has type '%s' which cannot hold dependency '%s' of type '%s'", target, dep.GetName(), t, childDep.GetName(), getInstanceType(childDep)))
+		}
+	}
+
+	return deps, errs
+}
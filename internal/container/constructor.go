@@ -6,101 +6,220 @@ import (
 	"reflect"
 
 	"github.com/Gobusters/ectoinject/dependency"
+	"github.com/Gobusters/ectoinject/ectoerrors"
 	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
 )
 
-func useDependencyConstructor(ctx context.Context, container *EctoContainer, dep dependency.Dependency, chain []dependency.Dependency) (context.Context, dependency.Dependency, error) {
+// cleanupType is the type of the cleanup func a constructor or provider may return after the dependency
+var cleanupType = reflect.TypeOf(func() {})
+
+func useDependencyConstructor(ctx context.Context, container *EctoContainer, dep dependency.Dependency, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, func(), error) {
 	constructor := dep.GetConstructor()
 
-	// get the number of args for the constructor
-	argCount := constructor.Type.NumIn()
-	// create the args for the constructor. The first arg is the struct instance
-	args := make([]reflect.Value, argCount)
-
-	// loop through the constructor args and get the instances
-	for i := 0; i < argCount; i++ {
-		// get the type of the constructor arg. Add 1 to the index to skip the struct instance
-		paramType := constructor.Type.In(i)
-		// check if the param is a pointer
-		isPtr := paramType.Kind() == reflect.Ptr
-		if isPtr {
-			// if the param is a pointer, get the type of the value
-			paramType = paramType.Elem()
-		}
+	receiver, err := newConstructorReceiver(constructor)
+	if err != nil {
+		return ctx, reflect.Value{}, nil, err
+	}
 
-		if i == 0 {
-			// the first arg is the struct instance
-			val, err := ectoreflect.NewStructInstance(paramType)
-			if err != nil {
-				return ctx, dep, err
-			}
-
-			if isPtr {
-				// if the param is a pointer, get the pointer to the value
-				val = val.Addr()
-			}
-			args[i] = val
-			continue
-		}
-		// get the name of the param type
-		paramTypeName := ectoreflect.GetReflectTypeName(paramType)
+	ctx, val, cleanup, err := callDependencyFunc(ctx, container, dep, constructor.Name, constructor.Func, []reflect.Value{receiver}, chain, res)
+	if err != nil {
+		return ctx, val, cleanup, err
+	}
 
-		// if the arg is a context, add the context to the args
-		if paramTypeName == "context.Context" {
-			args[i] = reflect.ValueOf(ctx)
-			continue
-		}
+	return container.injectBuiltMethods(ctx, dep, val, cleanup, chain, res)
+}
 
-		// check if the dependency is the container
-		containerDep, ok := container.getContainerDependency(paramTypeName)
-		if ok {
-			// get the instance of the container
-			args[i] = reflect.ValueOf(containerDep)
-			continue
+// newConstructorReceiver creates the struct instance the constructor is called on. The first arg of a constructor is the struct instance
+func newConstructorReceiver(constructor reflect.Method) (reflect.Value, error) {
+	argType := constructor.Type.In(0)
+	paramType := argType
+	// check if the param is a pointer
+	isPtr := paramType.Kind() == reflect.Ptr
+	if isPtr {
+		// if the param is a pointer, get the type of the value
+		paramType = paramType.Elem()
+	}
+
+	val, err := ectoreflect.NewStructInstance(paramType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if isPtr {
+		// if the param is a pointer, get the pointer to the value
+		val = val.Addr()
+	}
+
+	return val, nil
+}
+
+// useDependencyProvider calls the provider func of the dependency with its args resolved from the container
+func useDependencyProvider(ctx context.Context, container *EctoContainer, dep dependency.Dependency, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, func(), error) {
+	ctx, val, cleanup, err := callDependencyFunc(ctx, container, dep, dep.GetProviderName(), dep.GetProvider(), nil, chain, res)
+	if err != nil {
+		return ctx, val, cleanup, err
+	}
+
+	return container.injectBuiltMethods(ctx, dep, val, cleanup, chain, res)
+}
+
+// injectBuiltMethods calls the inject methods of an instance built by a constructor or provider. If a method fails, the cleanup func of the instance is called
+func (container *EctoContainer) injectBuiltMethods(ctx context.Context, dep dependency.Dependency, val reflect.Value, cleanup func(), chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, func(), error) {
+	ctx, err := container.injectMethods(ctx, dep, val, chain, res)
+	if err != nil {
+		if cleanup != nil {
+			cleanup()
 		}
+		return ctx, reflect.Value{}, nil, err
+	}
 
-		// check if the param is a dependency
-		childDep, ok := container.container[paramTypeName]
-		if !ok {
-			return ctx, dep, fmt.Errorf("dependency '%s' has unregistered dependency '%s' in '%s' func", dep.GetName(), paramTypeName, constructor.Name)
+	return ctx, val, cleanup, nil
+}
+
+// callDependencyFunc resolves the remaining args of the func, calls it and returns its first result and the cleanup func it returned, if any.
+// An error returned by the func is wrapped in a ConstructorError
+// funcName: The name of the func
+// fn: The func to call. Returns the dependency, optionally a cleanup func and optionally an error
+// preset: The args that are not resolved from the container by index, such as the struct instance of a constructor. Invalid values are resolved from the container
+func callDependencyFunc(ctx context.Context, container *EctoContainer, dep dependency.Dependency, funcName string, fn reflect.Value, preset []reflect.Value, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, func(), error) {
+	fnType := fn.Type()
+	args := make([]reflect.Value, fnType.NumIn())
+	copy(args, preset)
+
+	// loop through the remaining args and get the instances
+	for i := range args {
+		if args[i].IsValid() {
+			continue
 		}
 
-		// get the instance of the dependency
 		var err error
-		ctx, childDep, err = container.getDependency(ctx, childDep, chain)
+		ctx, args[i], err = container.getArg(ctx, dep, funcName, fnType.In(i), chain, res)
 		if err != nil {
-			return ctx, dep, err
-		}
-
-		val := childDep.GetValue()
-		// check if the param is a pointer
-		if isPtr || paramType.Kind() == reflect.Interface {
-			// if the param is a pointer, get the pointer to the value
-			val = val.Addr()
+			return ctx, reflect.Value{}, nil, err
 		}
-
-		// add the dependency to the args
-		args[i] = val
 	}
 
-	// call the constructor with the args
-	result := constructor.Func.Call(args)
+	// call the func with the args
+	result := fn.Call(args)
 
 	if len(result) == 0 {
-		return ctx, dep, fmt.Errorf("constructor '%s' on dependnecy '%s' did not return an instance", constructor.Name, dep.GetName())
+		return ctx, reflect.Value{}, nil, fmt.Errorf("constructor '%s' on dependnecy '%s' did not return an instance", funcName, dep.GetName())
 	}
 
-	_ = dep.SetValue(result[0])
-	container.container[dep.GetName()] = dep
+	var cleanup func()
+	if len(result) > 1 && result[1].Type() == cleanupType {
+		if !result[1].IsNil() {
+			cleanup = result[1].Interface().(func())
+		}
+		result = append(result[:1], result[2:]...)
+	}
 
 	if len(result) == 1 {
-		return ctx, dep, nil
+		return ctx, result[0], cleanup, nil
 	}
 
 	err, ok := result[1].Interface().(error)
 	if ok {
-		return ctx, dep, err
+		// the func failed so its cleanup func is not kept
+		return ctx, result[0], nil, &ectoerrors.ConstructorError{Dependency: dep.GetName(), Chain: getChainNames(chain), Cause: err}
+	}
+
+	return ctx, result[0], cleanup, nil
+}
+
+// getArg gets the value passed to an arg of a func called by the container. context.Context args get the context of the resolution,
+// parameter objects have their fields injected and every other arg is resolved by its type
+// dep: The dependency the func is called for
+// funcName: The name of the func
+// argType: The type of the arg
+func (container *EctoContainer) getArg(ctx context.Context, dep dependency.Dependency, funcName string, argType reflect.Type, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, error) {
+	// get the name of the param type
+	paramTypeName := ectoreflect.GetReflectTypeName(argType)
+
+	// if the arg is a context, add the context to the args
+	if paramTypeName == "context.Context" {
+		return ctx, reflect.ValueOf(withResolution(ctx, res)), nil
+	}
+
+	// inject the fields of a parameter object
+	if isParamObject(argType) {
+		return container.getParamObject(ctx, dep, funcName, argType, chain, res)
+	}
+
+	// check if the dependency is the container
+	containerDep, ok := container.getContainerDependency(paramTypeName)
+	if ok {
+		// get the instance of the container
+		return ctx, reflect.ValueOf(containerDep), nil
+	}
+
+	// pass a wrapper that resolves the dependency when it is used
+	if isDeferred(argType) {
+		ctx, deferred, ok := container.getDeferred(ctx, dep, argType, "")
+		if !ok {
+			return ctx, reflect.Value{}, &ectoerrors.NotFoundError{Dependency: getDeferredName(argType, ""), Chain: getChainNames(chain), Func: funcName}
+		}
+		return ctx, deferred, nil
+	}
+
+	// check if the param is a dependency
+	childDep, ok := container.findRegistration(argType, "")
+	if !ok && container.hasCollection(argType) {
+		// pass every registration of the element type
+		return container.getCollection(ctx, dep, argType, chain, res)
+	}
+
+	if !ok {
+		return ctx, reflect.Value{}, &ectoerrors.NotFoundError{Dependency: paramTypeName, Chain: getChainNames(chain), Func: funcName}
+	}
+
+	// get the instance of the dependency
+	ctx, childVal, err := container.getDependency(ctx, childDep, chain, res)
+	if err != nil {
+		return ctx, reflect.Value{}, err
+	}
+
+	if !childVal.IsValid() {
+		return ctx, reflect.Value{}, fmt.Errorf("dependency '%s' has nil dependency '%s' in '%s' func", dep.GetName(), paramTypeName, funcName)
+	}
+
+	// convert the instance to the type of the param
+	val, err := ectoreflect.CastType(argType, ectoreflect.GetPointerOfValue(childVal))
+	if err != nil {
+		return ctx, reflect.Value{}, fmt.Errorf("failed to pass dependency '%s' to '%s' func of dependency '%s': %w", paramTypeName, funcName, dep.GetName(), err)
+	}
+
+	return ctx, val, nil
+}
+
+// getParamObject creates a parameter object and injects its fields. context.Context fields get the context of the resolution
+// argType: The type of the parameter object. Either a struct or a pointer to a struct
+func (container *EctoContainer) getParamObject(ctx context.Context, dep dependency.Dependency, funcName string, argType reflect.Type, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, error) {
+	paramType := argType
+	if paramType.Kind() == reflect.Ptr {
+		paramType = paramType.Elem()
+	}
+
+	val := reflect.New(paramType)
+	for i := 0; i < paramType.NumField(); i++ {
+		if field := paramType.Field(i); field.Type == contextType && field.IsExported() {
+			val.Elem().Field(i).Set(reflect.ValueOf(withResolution(ctx, res)))
+		}
+	}
+
+	fields, err := container.getParamFields(paramType)
+	if err != nil {
+		return ctx, reflect.Value{}, fmt.Errorf("arg of '%s' func of dependency '%s' cannot be injected: %w", funcName, dep.GetName(), err)
+	}
+
+	ctx, err = container.setFields(ctx, dep, val.Elem(), fields, funcName, chain, res)
+	if err != nil {
+		return ctx, reflect.Value{}, err
+	}
+
+	if argType.Kind() != reflect.Ptr {
+		return ctx, val.Elem(), nil
 	}
 
-	return ctx, dep, nil
+	return ctx, val, nil
 }
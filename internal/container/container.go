@@ -4,9 +4,14 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Gobusters/ectoinject/dependency"
 	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/ectoerrors"
+	ectodependency "github.com/Gobusters/ectoinject/internal/dependency"
 	"github.com/Gobusters/ectoinject/internal/logging"
 	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
 	"github.com/Gobusters/ectoinject/internal/scope"
@@ -16,16 +21,32 @@ import (
 
 // Container for dependencies
 type EctoContainer struct {
-	ectocontainer.DIContainerConfig                                  // The configuration for the container
-	logger                          *logging.Logger                  // The logger to use
-	container                       map[string]dependency.Dependency // The container of dependencies
+	ectocontainer.DIContainerConfig                                           // The configuration for the container
+	logger                          *logging.Logger                           // The logger to use
+	mu                              sync.RWMutex                              // Guards container, names, order, priorities and decorators
+	container                       map[registrationKey]dependency.Dependency // The registered dependencies by type and name
+	names                           map[string][]registrationKey              // The keys of the registered dependencies by name. Unnamed dependencies are named after their type
+	order                           []registrationKey                         // The keys of the registered dependencies in registration order
+	priorities                      map[registrationKey]int                   // The priorities of registrations in collections
+	decorators                      map[reflect.Type][]dependency.Decorator   // The decorators of each registered type in registration order
+	singletons                      *scope.Scope                              // The cache of singleton instances
+	root                            *scope.Scope                              // The scope used for scoped dependencies when Get is called without a scope
+	closed                          atomic.Bool                               // Set once the container has been closed
+	lifecycleMu                     sync.Mutex                                // Guards running and stopHooks
+	running                         bool                                      // Set while the container is started
+	stopHooks                       []stopHook                                // The stop hooks of the started singletons in the order they were started
 }
 
 func NewEctoContainer(config ectocontainer.DIContainerConfig, logger *logging.Logger) *EctoContainer {
 	return &EctoContainer{
 		DIContainerConfig: config,
 		logger:            logger,
-		container:         make(map[string]dependency.Dependency),
+		container:         make(map[registrationKey]dependency.Dependency),
+		names:             make(map[string][]registrationKey),
+		priorities:        make(map[registrationKey]int),
+		decorators:        make(map[reflect.Type][]dependency.Decorator),
+		singletons:        scope.New(),
+		root:              scope.New(),
 	}
 }
 
@@ -34,7 +55,30 @@ func (container *EctoContainer) GetContainerID() string {
 }
 
 func (container *EctoContainer) AddDependency(dep dependency.Dependency) {
-	container.container[dep.GetName()] = dep
+	container.mu.Lock()
+	defer container.mu.Unlock()
+
+	container.addRegistration(dep)
+
+	// every field of a result struct is registered as its own dependency
+	if ectodependency.IsResult(dep.GetDependencyType()) {
+		for _, fieldDep := range ectodependency.NewResultDependencies(dep, container.InjectTagName) {
+			container.addRegistration(fieldDep)
+		}
+	}
+}
+
+// addRegistration adds or replaces the registration of the dependency. The caller must hold mu
+func (container *EctoContainer) addRegistration(dep dependency.Dependency) {
+	key := keyOf(dep)
+	if _, ok := container.container[key]; !ok {
+		container.names[dep.GetName()] = append(container.names[dep.GetName()], key)
+		container.order = append(container.order, key)
+	}
+
+	container.container[key] = dep
+	// a replaced registration must not resolve to the instance of the previous registration
+	container.singletons.Forget(container.cacheKey(dep))
 }
 
 func (container *EctoContainer) GetConstructorFuncName() string {
@@ -46,6 +90,10 @@ func (container *EctoContainer) Get(ctx context.Context, name string) (context.C
 		return ctx, nil, fmt.Errorf("dependency name cannot be empty")
 	}
 
+	if container.closed.Load() {
+		return ctx, nil, fmt.Errorf("container '%s' is closed", container.ID)
+	}
+
 	// check if the dependency is the container
 	containerDep, ok := container.getContainerDependency(name)
 	if ok {
@@ -53,37 +101,69 @@ func (container *EctoContainer) Get(ctx context.Context, name string) (context.C
 	}
 
 	// check if the dependency is registered
-	dep, ok := container.container[name]
+	dep, ok, err := container.getRegistration(name)
+	if err != nil {
+		return ctx, nil, err
+	}
 	if !ok {
-		return ctx, nil, fmt.Errorf("dependency for %s not found", name)
+		return ctx, nil, &ectoerrors.NotFoundError{Dependency: name}
+	}
+
+	return container.resolve(ctx, dep)
+}
+
+// GetByType gets the dependency registered for the type. Registrations are matched by type and name, then by the pointer or element type, then by the name alone
+// ctx: The context to use
+// t: The type of the dependency
+// name: (optional) The name of the dependency
+func (container *EctoContainer) GetByType(ctx context.Context, t reflect.Type, name string) (context.Context, any, error) {
+	if container.closed.Load() {
+		return ctx, nil, fmt.Errorf("container '%s' is closed", container.ID)
 	}
 
+	lookupName := name
+	if lookupName == "" {
+		lookupName = ectoreflect.GetReflectTypeName(t)
+	}
+
+	// check if the dependency is the container
+	containerDep, ok := container.getContainerDependency(lookupName)
+	if ok {
+		return ctx, containerDep, nil
+	}
+
+	// check if the dependency is registered
+	dep, ok := container.findRegistration(t, name)
+	if !ok {
+		return ctx, nil, &ectoerrors.NotFoundError{Dependency: lookupName}
+	}
+
+	return container.resolve(ctx, dep)
+}
+
+// resolve gets the instance of the registered dependency as the type it was registered as
+func (container *EctoContainer) resolve(ctx context.Context, dep dependency.Dependency) (context.Context, any, error) {
 	// get the instance of the dependency
-	var err error
-	ctx, dep, err = container.getDependency(ctx, dep, []dependency.Dependency{})
+	ctx, val, err := container.getDependency(ctx, dep, []dependency.Dependency{}, resolutionFromContext(ctx))
 	if err != nil {
 		return ctx, nil, err
 	}
 
 	// check if the dependency has a value
-	if !dep.HasValue() {
-		return ctx, nil, fmt.Errorf("dependency for %s is nil", name)
+	if !val.IsValid() {
+		return ctx, nil, fmt.Errorf("dependency for %s is nil", dep.GetName())
 	}
 
 	// return the value
-	instance, err := dep.GetInstance()
+	instance, err := getInstance(dep, val)
 	return ctx, instance, err
 }
 
-func (container *EctoContainer) getDependency(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency) (context.Context, dependency.Dependency, error) {
-	defer func() {
-		container.container[dep.GetName()] = dep // update the container with the new instance
-	}()
-
+func (container *EctoContainer) getDependency(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, error) {
 	// check for circular dependency
-	err := checkForCircularDependency(dep.GetName(), chain)
+	err := checkForCircularDependency(dep, chain)
 	if err != nil {
-		return ctx, dep, err
+		return ctx, reflect.Value{}, err
 	}
 
 	// validate lifecycles
@@ -93,101 +173,163 @@ func (container *EctoContainer) getDependency(ctx context.Context, dep dependenc
 		if container.AllowCaptiveDependencies {
 			container.logger.Warn(ctx, "Lifecycle validation error: %v", err)
 		} else {
-			return ctx, dep, err // return the error
+			return ctx, reflect.Value{}, err // return the error
 		}
 	}
 
 	// add this dependency to the chain
 	chain = append(chain, dep)
 
-	// if the dependency is a singleton and dependency has a value already, return the value
-	if dep.HasValue() && dep.GetLifecycle() == lifecycles.Singleton {
-		return ctx, dep, nil
-	}
-
-	// if the dependency is a scoped, check the scoped cache
-	if dep.GetLifecycle() == lifecycles.Scoped {
-		// check the scoped cache
-		scopedDep, ok := scope.GetScopedDependency(ctx, dep.GetName())
-		if ok {
-			return ctx, scopedDep, nil // return the scoped dependency
+	// pick the creation strategy and decorate the instance, then apply the lifecycle caching
+	create := func(ctx context.Context) (context.Context, reflect.Value, func(context.Context) error, error) {
+		ctx, val, cleanup, err := container.createDependency(ctx, dep, chain, res)
+		if err != nil {
+			return ctx, val, nil, err
 		}
 
-		// create a new instance
-		ctx, dep, err = container.getDependencyWithDependencies(ctx, dep, chain)
-		if err != nil {
-			return ctx, dep, err
+		var dispose func(context.Context) error
+		if cleanup != nil {
+			// the cleanup func replaces disposing the instance
+			dispose = container.getCleanupDisposeFunc(dep, cleanup)
 		}
 
-		// add the instance to the scoped cache
-		ctx = scope.AddScopedDependency(ctx, dep)
-		return ctx, dep, nil
+		return container.decorate(ctx, dep, val, dispose, chain, res)
+	}
+
+	switch dep.GetLifecycle() {
+	case lifecycles.Singleton:
+		// build once and share the instance
+		return container.getCached(ctx, container.singletons, dep, chain, res, create)
+	case lifecycles.Scoped:
+		// build once per scope
+		return container.getScoped(ctx, dep, chain, res, create)
+	default:
+		// transient dependencies are never cached
+		return container.getTransient(ctx, dep, create)
+	}
+}
+
+// createDependency creates a new instance of the dependency using its instance func, its provider, its constructor or by injecting its fields.
+// Returns the cleanup func returned by the provider or constructor, if any
+func (container *EctoContainer) createDependency(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, func(), error) {
+	// fields of a result struct are taken from the result struct so they share one call of its constructor
+	if parent, index := dep.GetResultOf(); parent != nil {
+		ctx, val, err := container.getResultField(ctx, parent, index, chain, res)
+		return ctx, val, nil, err
 	}
 
 	// if the user has provided a GetInstanceFunc, use that to get the instance
 	instanceFunc := dep.GetInstanceFunc()
 	if instanceFunc != nil {
-		instance, err := instanceFunc(ctx)
+		instance, err := instanceFunc(withResolution(ctx, res))
 		if err != nil {
-			return ctx, dep, err
+			return ctx, reflect.Value{}, nil, &ectoerrors.ConstructorError{Dependency: dep.GetName(), Chain: getChainNames(chain), Cause: err}
 		}
 
-		err = dep.SetValue(reflect.ValueOf(instance))
-		if err != nil {
-			return ctx, dep, err
-		}
-		return ctx, dep, nil
+		return ctx, reflect.ValueOf(instance), nil, nil
+	}
+
+	// create the func of a factory
+	if dep.GetProduct() != nil {
+		ctx, factory := container.makeFactory(ctx, dep)
+		return ctx, factory, nil, nil
+	}
+
+	// call the provider func if the dependency was registered with one
+	if dep.HasProvider() {
+		return useDependencyProvider(ctx, container, dep, chain, res)
 	}
 
 	// use the dependency's constructor if it has one
 	if dep.HasConstructor() {
-		return useDependencyConstructor(ctx, container, dep, chain)
+		return useDependencyConstructor(ctx, container, dep, chain, res)
 	} else if container.RequireConstructor {
 		container.logger.Warn(ctx, "dependency '%s' does not have a constructor", dep.GetName())
-		return ctx, dep, nil
+		return ctx, reflect.Value{}, nil, nil
 	}
 
 	// create an instance of the dependency
-	ctx, dep, err = container.getDependencyWithDependencies(ctx, dep, chain)
-	if err != nil {
-		return ctx, dep, err
+	ctx, val, err := container.getDependencyWithDependencies(ctx, dep, chain, res)
+	return ctx, val, nil, err
+}
+
+// getResultField gets the result struct from the parent dependency, then the field of the struct at the index
+func (container *EctoContainer) getResultField(ctx context.Context, parent dependency.Dependency, index []int, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, error) {
+	ctx, val, err := container.getDependency(ctx, parent, chain, res)
+	if err != nil || !val.IsValid() {
+		return ctx, reflect.Value{}, err
 	}
 
-	return ctx, dep, nil
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return ctx, reflect.Value{}, fmt.Errorf("dependency '%s' returned a nil result", parent.GetName())
+		}
+		val = val.Elem()
+	}
+
+	return ctx, val.FieldByIndex(index), nil
 }
 
-func (container *EctoContainer) getDependencyWithDependencies(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency) (context.Context, dependency.Dependency, error) {
+func (container *EctoContainer) getDependencyWithDependencies(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, error) {
 	valueType := dep.GetDependencyValueType()
 	// create a new struct value for the dependency
 	if valueType.Kind() != reflect.Struct {
-		return ctx, dep, fmt.Errorf("dependency '%s' has type '%s' which is not a struct", dep.GetName(), valueType.Name())
+		return ctx, reflect.Value{}, fmt.Errorf("dependency '%s' has type '%s' which is not a struct", dep.GetName(), valueType.Name())
 	}
 
 	val, err := ectoreflect.NewStructInstance(valueType)
 	if err != nil {
-		return ctx, dep, fmt.Errorf("failed to create new struct instance for dependency '%s': %w", dep.GetName(), err)
+		return ctx, reflect.Value{}, fmt.Errorf("failed to create new struct instance for dependency '%s': %w", dep.GetName(), err)
 	}
-	err = dep.SetValue(val)
+
+	// Set dependencies
+	ctx, err = container.setDependencies(ctx, dep, val, chain, res)
 	if err != nil {
-		return ctx, dep, err
+		return ctx, val, err
 	}
 
-	// Set dependencies
-	ctx, dep, err = container.setDependencies(ctx, dep, chain)
+	ctx, err = container.injectMethods(ctx, dep, val, chain, res)
 	if err != nil {
-		return ctx, dep, err
+		return ctx, val, err
 	}
 
-	return ctx, dep, nil
+	// let the dependency validate its fields and derive state now that they are injected
+	err = container.initDependency(ctx, val, chain, res)
+	if err != nil {
+		return ctx, val, err
+	}
+
+	return ctx, val, nil
 }
 
-func (container *EctoContainer) setDependencies(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency) (context.Context, dependency.Dependency, error) {
-	val := dep.GetValue()
+// initDependency calls the init func of a field injected dependency. The init func may accept a context.Context and may return an error
+func (container *EctoContainer) initDependency(ctx context.Context, val reflect.Value, chain []dependency.Dependency, res *resolution) error {
+	if container.InitFuncName == "" {
+		return nil
+	}
+
+	init := getMethodHook(ectoreflect.GetPointerOfValue(val), container.InitFuncName)
+	if init == nil {
+		return nil
+	}
+
+	err := init(withResolution(ctx, res))
+	if err != nil {
+		return fmt.Errorf("failed to initialize dependency '%s'. Dependency chain: %s: %w", chain[len(chain)-1].GetName(), formatChain(chain), err)
+	}
+
+	return nil
+}
 
+func (container *EctoContainer) setDependencies(ctx context.Context, dep dependency.Dependency, val reflect.Value, chain []dependency.Dependency, res *resolution) (context.Context, error) {
 	// check if the dependency is a pointer
 	if val.Kind() != reflect.Ptr {
 		if !val.CanAddr() {
-			return ctx, dep, fmt.Errorf("failed to get address of struct instance for dependency '%s'", dep.GetName())
+			return ctx, fmt.Errorf("failed to get address of struct instance for dependency '%s'", dep.GetName())
 		}
 		// if the dependency is not a pointer, get the pointer to the value
 		val = val.Addr()
@@ -195,29 +337,36 @@ func (container *EctoContainer) setDependencies(ctx context.Context, dep depende
 
 	val = val.Elem()
 	if val.Kind() != reflect.Struct {
-		return ctx, dep, fmt.Errorf("instance of dependency '%s' must be a pointer to a struct but is %s", dep.GetName(), val.Kind())
+		return ctx, fmt.Errorf("instance of dependency '%s' must be a pointer to a struct but is %s", dep.GetName(), val.Kind())
 	}
 
-	t := val.Type()
-	for i := 0; i < val.NumField(); i++ {
-		field := t.Field(i)
-		tag := field.Tag.Get(container.InjectTagName)
-
-		if (tag == "" && container.RequireInjectTag) || tag == "-" {
-			continue // skip this field
-		}
+	fields, err := container.getInjectFields(val.Type())
+	if err != nil {
+		return ctx, fmt.Errorf("dependency '%s' cannot be injected: %w", dep.GetName(), err)
+	}
 
-		canSet := val.Field(i).CanSet()
+	return container.setFields(ctx, dep, val, fields, "", chain, res)
+}
 
-		if !canSet && !container.AllowUnsafeDependencies {
-			continue // skip this field
+// setFields injects the dependencies of the fields into the struct
+// val: The addressable struct to inject
+// fields: The fields to inject
+// funcName: The name of the func the struct is passed to when it is a parameter object. Empty when the struct is a dependency
+func (container *EctoContainer) setFields(ctx context.Context, dep dependency.Dependency, val reflect.Value, fields []injectField, funcName string, chain []dependency.Dependency, res *resolution) (context.Context, error) {
+	for _, f := range fields {
+		field, typeName := f.StructField, f.dependencyName()
+		if f.assisted {
+			continue // set by the factory func that creates the dependency
 		}
 
-		reflectName := ectoreflect.GetReflectTypeName(field.Type)
-
-		typeName := tag
-		if typeName == "" {
-			typeName = reflectName
+		if f.inline {
+			// inject the fields of the struct of the field
+			var err error
+			ctx, err = container.setInlineField(ctx, dep, val, f, funcName, chain, res)
+			if err != nil {
+				return ctx, err
+			}
+			continue
 		}
 
 		// check if the dependency is the container
@@ -225,37 +374,98 @@ func (container *EctoContainer) setDependencies(ctx context.Context, dep depende
 		if ok {
 			err := ectoreflect.SetField(val, field, reflect.ValueOf(containerDep))
 			if err != nil {
-				return ctx, dep, fmt.Errorf("failed to set field '%s' on struct instance for dependency '%s': %w", field.Name, dep.GetName(), err)
+				return ctx, container.setFieldError(dep, field, funcName, err)
+			}
+			continue
+		}
+
+		if isDeferred(field.Type) {
+			// inject a wrapper that resolves the dependency when it is used
+			var deferred reflect.Value
+			ctx, deferred, ok = container.getDeferred(ctx, dep, field.Type, f.name)
+			if !ok {
+				err := &ectoerrors.NotFoundError{Dependency: getDeferredName(field.Type, f.name), Chain: getChainNames(chain), Func: funcName}
+				if container.isMissingAllowed(f, funcName) {
+					container.logger.Info(ctx, "%s", err)
+					continue
+				}
+				return ctx, err
+			}
+
+			err := ectoreflect.SetField(val, field, deferred)
+			if err != nil {
+				return ctx, container.setFieldError(dep, field, funcName, err)
+			}
+			continue
+		}
+
+		childDep, ok := container.findRegistration(field.Type, f.name)
+		if !ok && f.name == "" && container.hasCollection(field.Type) {
+			// inject every registration of the element type
+			var collection reflect.Value
+			var err error
+			ctx, collection, err = container.getCollection(ctx, dep, field.Type, chain, res)
+			if err != nil {
+				return ctx, err
+			}
+
+			err = ectoreflect.SetField(val, field, collection)
+			if err != nil {
+				return ctx, container.setFieldError(dep, field, funcName, err)
 			}
 			continue
 		}
 
-		childDep, ok := container.container[typeName]
 		if !ok {
-			msg := fmt.Sprintf("%s has a dependency on %s, but it is not registered", dep.GetName(), typeName)
-			if container.AllowMissingDependencies {
-				container.logger.Info(ctx, "%s", msg)
+			err := &ectoerrors.NotFoundError{Dependency: typeName, Chain: getChainNames(chain), Func: funcName}
+			if container.isMissingAllowed(f, funcName) {
+				container.logger.Info(ctx, "%s", err)
 				continue
 			}
-			return ctx, dep, fmt.Errorf("%s", msg)
+			return ctx, err
 		}
 
+		var childVal reflect.Value
 		var err error
-		ctx, childDep, err = container.getDependency(ctx, childDep, chain)
+		ctx, childVal, err = container.getDependency(ctx, childDep, chain, res)
 		if err != nil {
-			return ctx, dep, err
+			return ctx, err
 		}
 
-		container.container[typeName] = childDep
+		if !childVal.IsValid() {
+			continue // nothing to inject
+		}
 
-		err = ectoreflect.SetField(val, field, childDep.GetValue())
+		err = ectoreflect.SetField(val, field, childVal)
 		if err != nil {
-			return ctx, dep, fmt.Errorf("failed to set field '%s' on struct instance for dependency '%s': %w", field.Name, dep.GetName(), err)
+			return ctx, container.setFieldError(dep, field, funcName, err)
+		}
+	}
+
+	return ctx, nil
+}
+
+// setInlineField injects the fields of the struct of an inline field. A nil pointer to the struct is set to a new struct
+// val: The addressable struct that has the inline field
+func (container *EctoContainer) setInlineField(ctx context.Context, dep dependency.Dependency, val reflect.Value, f injectField, funcName string, chain []dependency.Dependency, res *resolution) (context.Context, error) {
+	inline := ectoreflect.GetSettableField(val, f.StructField)
+	if inline.Kind() == reflect.Ptr {
+		if inline.IsNil() {
+			inline.Set(reflect.New(inline.Type().Elem()))
 		}
+		inline = inline.Elem()
 	}
 
-	err := dep.SetValue(val)
-	return ctx, dep, err
+	return container.setFields(ctx, dep, inline, f.fields, funcName, chain, res)
+}
+
+// setFieldError creates the error for a field that could not be set
+func (container *EctoContainer) setFieldError(dep dependency.Dependency, field reflect.StructField, funcName string, err error) error {
+	if funcName != "" {
+		return fmt.Errorf("failed to set field '%s' on arg of '%s' func of dependency '%s': %w", field.Name, funcName, dep.GetName(), err)
+	}
+
+	return fmt.Errorf("failed to set field '%s' on struct instance for dependency '%s': %w", field.Name, dep.GetName(), err)
 }
 
 func (container *EctoContainer) getContainerDependency(name string) (any, bool) {
@@ -270,43 +480,83 @@ func (container *EctoContainer) getContainerDependency(name string) (any, bool)
 	return dep, false
 }
 
-func checkForCircularDependency(depName string, chain []dependency.Dependency) error {
-	for _, dep := range chain {
-		if dep.GetName() == depName {
-			depChain := ""
-			for _, dep := range chain {
-				depChain += fmt.Sprintf("%s -> ", dep.GetName())
-			}
-			return fmt.Errorf("circular dependency detected for '%s'. Dependency chain: %s%s", depName, depChain, depName)
+// getInstance casts the instance of the dependency to the type it was registered as
+func getInstance(dep dependency.Dependency, val reflect.Value) (any, error) {
+	instance, err := ectoreflect.CastType(dep.GetDependencyType(), ectoreflect.GetPointerOfValue(val))
+	if err != nil {
+		return nil, fmt.Errorf("failed to cast dependency '%s' to type '%s': %w", dep.GetName(), dep.GetDependencyType().Name(), err)
+	}
+
+	return instance.Interface(), nil
+}
+
+func checkForCircularDependency(dep dependency.Dependency, chain []dependency.Dependency) error {
+	key := keyOf(dep)
+	for _, parent := range chain {
+		if keyOf(parent) == key {
+			return circularDependencyError(dep.GetName(), getChainNames(chain))
 		}
 	}
 	return nil
 }
 
+// circularDependencyError creates the error for a dependency that depends on itself
+// depName: The name of the repeated dependency
+// chain: The names of the dependencies resolved before the repeated dependency
+func circularDependencyError(depName string, chain []string) error {
+	return &ectoerrors.CircularDependencyError{Dependency: depName, Chain: append(append([]string{}, chain...), depName)}
+}
+
+// getChainNames gets the names of the dependencies in the chain
+func getChainNames(chain []dependency.Dependency) []string {
+	names := make([]string, len(chain))
+	for i, dep := range chain {
+		names[i] = dep.GetName()
+	}
+	return names
+}
+
+// formatChain formats the dependency chain as `root -> child -> leaf`
+func formatChain(chain []dependency.Dependency) string {
+	return strings.Join(getChainNames(chain), " -> ")
+}
+
+// isCaptive checks if the child dependency would be held captive by the parent because the parent has a longer lifecycle
+func isCaptive(parent, child dependency.Dependency) bool {
+	switch child.GetLifecycle() {
+	case lifecycles.Transient:
+		return parent.GetLifecycle() == lifecycles.Scoped || parent.GetLifecycle() == lifecycles.Singleton
+	case lifecycles.Scoped:
+		return parent.GetLifecycle() == lifecycles.Singleton
+	}
+	return false
+}
+
+// captiveDependencyError creates the error for a child dependency that would be held captive by the parent
+// parent: The dependency with the longer lifecycle
+// child: The dependency that would be held captive
+// chain: The names of the dependencies resolved from the root dependency down to the child
+func captiveDependencyError(parent, child dependency.Dependency, chain []string) error {
+	return &ectoerrors.CaptiveDependencyError{
+		Parent:          parent.GetName(),
+		ParentLifecycle: parent.GetLifecycle(),
+		Child:           child.GetName(),
+		ChildLifecycle:  child.GetLifecycle(),
+		Chain:           chain,
+	}
+}
+
 func (container *EctoContainer) validateLifecycles(ctx context.Context, dep dependency.Dependency, chain []dependency.Dependency) error {
-	if dep.GetLifecycle() == lifecycles.Transient {
-		// check if any of the parent dependencies are scoped or singleton
-		for _, parent := range chain {
-			if parent.GetLifecycle() == lifecycles.Scoped || parent.GetLifecycle() == lifecycles.Singleton {
-				if container.AllowCaptiveDependencies {
-					container.logger.Info(ctx, "captive dependency: %s is a %s but has a transient dependency %s. %s will behave as a %s", parent.GetName(), parent.GetLifecycle(), dep.GetName(), dep.GetName(), parent.GetLifecycle())
-				} else {
-					return fmt.Errorf("captive dependency error: %s is a %s but has a transient dependency %s", parent.GetName(), parent.GetLifecycle(), dep.GetName())
-				}
-			}
+	// check if any of the parent dependencies have a longer lifecycle
+	for _, parent := range chain {
+		if !isCaptive(parent, dep) {
+			continue
 		}
-	}
 
-	if dep.GetLifecycle() == lifecycles.Scoped {
-		// check if any of the parent dependencies are singleton
-		for _, parent := range chain {
-			if parent.GetLifecycle() == lifecycles.Singleton {
-				if container.AllowCaptiveDependencies {
-					container.logger.Info(ctx, "captive dependency: %s is a %s but has a scoped dependency %s. %s will behave as a %s", parent.GetName(), parent.GetLifecycle(), dep.GetName(), dep.GetName(), parent.GetLifecycle())
-				} else {
-					return fmt.Errorf("captive dependency error: %s is a %s but has a scoped dependency %s", parent.GetName(), parent.GetLifecycle(), dep.GetName())
-				}
-			}
+		if container.AllowCaptiveDependencies {
+			container.logger.Info(ctx, "captive dependency: %s is a %s but has a %s dependency %s. %s will behave as a %s", parent.GetName(), parent.GetLifecycle(), dep.GetLifecycle(), dep.GetName(), dep.GetName(), parent.GetLifecycle())
+		} else {
+			return captiveDependencyError(parent, dep, append(getChainNames(chain), dep.GetName()))
 		}
 	}
 
@@ -0,0 +1,92 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+	"github.com/Gobusters/ectoinject/lifecycles"
+)
+
+// AddDecorator adds a decorator for the instances of every registration of its type. Decorators of a type run in the order they were added
+func (container *EctoContainer) AddDecorator(dec dependency.Decorator) {
+	container.mu.Lock()
+	defer container.mu.Unlock()
+
+	// decorators match registrations of the pointer or element type of their type, the same way dependencies are looked up
+	t := dec.GetDecoratedType()
+	other := variantOf(t)
+	container.decorators[t] = append(container.decorators[t], dec)
+	if other != nil {
+		container.decorators[other] = append(container.decorators[other], dec)
+	}
+
+	// singletons built before the decorator was added must be built again to be decorated
+	for key, dep := range container.container {
+		if key.Type == t || (other != nil && key.Type == other) {
+			container.singletons.Forget(container.cacheKey(dep))
+		}
+	}
+}
+
+// getDecorators gets the decorators of the type in the order they were added
+func (container *EctoContainer) getDecorators(t reflect.Type) []dependency.Decorator {
+	container.mu.RLock()
+	defer container.mu.RUnlock()
+
+	return container.decorators[t]
+}
+
+// decorate passes a new instance of the dependency through the decorators of its type. The instance built by the container is still the one disposed,
+// so the dispose func of the instance is returned unless the dependency is transient
+// val: The instance built by the container
+// dispose: (optional) The func that disposes the instance in place of its dispose method
+func (container *EctoContainer) decorate(ctx context.Context, dep dependency.Dependency, val reflect.Value, dispose func(context.Context) error, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, func(context.Context) error, error) {
+	decorators := container.getDecorators(dep.GetDependencyType())
+	if len(decorators) == 0 || !val.IsValid() {
+		return ctx, val, dispose, nil
+	}
+
+	if dispose == nil && dep.GetLifecycle() != lifecycles.Transient {
+		dispose = container.getDisposeFunc(dep, val)
+	}
+
+	for _, dec := range decorators {
+		inner, err := ectoreflect.CastType(dec.GetDecoratedType(), ectoreflect.GetPointerOfValue(val))
+		if err != nil {
+			return ctx, reflect.Value{}, nil, fmt.Errorf("failed to pass dependency '%s' to decorator '%s': %w", dep.GetName(), dec.GetFuncName(), err)
+		}
+
+		preset := make([]reflect.Value, dec.GetInnerIndex()+1)
+		preset[dec.GetInnerIndex()] = inner
+
+		ctx, val, _, err = callDependencyFunc(ctx, container, dep, dec.GetFuncName(), dec.GetFunc(), preset, chain, res)
+		if err != nil {
+			return ctx, reflect.Value{}, nil, err
+		}
+
+		if val.IsValid() && val.Kind() != reflect.Ptr && val.Kind() != reflect.Interface && dec.GetDecoratedType() != dep.GetDependencyType() {
+			// keep a pointer to a value returned for a pointer registration so every resolution shares the decorated instance
+			ptr := reflect.New(val.Type())
+			ptr.Elem().Set(val)
+			val = ptr
+		}
+	}
+
+	return ctx, val, dispose, nil
+}
+
+// validateDecorators checks the args of the decorators of the dependency. Returns the dependencies of the decorators and the problems found
+func (container *EctoContainer) validateDecorators(dep dependency.Dependency) ([]dependency.Dependency, []error) {
+	var children []dependency.Dependency
+	var errs []error
+	for _, dec := range container.getDecorators(dep.GetDependencyType()) {
+		decChildren, decErrs := container.validateArgs(dep, dec.GetFunc().Type(), 0, dec.GetFuncName(), map[int]bool{dec.GetInnerIndex(): true})
+		children = append(children, decChildren...)
+		errs = append(errs, decErrs...)
+	}
+
+	return children, errs
+}
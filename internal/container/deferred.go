@@ -0,0 +1,84 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	"github.com/Gobusters/ectoinject/ectoerrors"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+var deferredType = reflect.TypeOf((*dependency.Deferred)(nil)).Elem()
+
+// isDeferred checks if the type is a wrapper that resolves its dependency when it is used, such as ectoinject.Lazy
+func isDeferred(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(deferredType)
+}
+
+// getDeferredType gets the type of the dependency wrapped by the deferred type
+func getDeferredType(t reflect.Type) reflect.Type {
+	return reflect.New(t).Interface().(dependency.Deferred).DependencyType()
+}
+
+// getDeferredName gets the name of the dependency wrapped by the deferred type. Unnamed dependencies are named after their type
+func getDeferredName(t reflect.Type, name string) string {
+	if name == "" {
+		return ectoreflect.GetReflectTypeName(getDeferredType(t))
+	}
+
+	return name
+}
+
+// getDeferred creates a wrapper of the deferred type. The wrapper resolves its dependency with the container and the scope of the context when it is used.
+// Wrappers injected into singletons use the root scope of the container. Returns false if the wrapped dependency is not registered
+// dep: The dependency the wrapper is injected into
+// t: The deferred type
+// name: (optional) The name of the wrapped dependency
+func (container *EctoContainer) getDeferred(ctx context.Context, dep dependency.Dependency, t reflect.Type, name string) (context.Context, reflect.Value, bool) {
+	wrapper := reflect.New(t)
+	deferred := wrapper.Interface().(dependency.Deferred)
+	depType := deferred.DependencyType()
+
+	if _, ok := container.getContainerDependency(getDeferredName(t, name)); !ok {
+		if _, ok := container.findRegistration(depType, name); !ok {
+			return ctx, reflect.Value{}, false
+		}
+	}
+
+	ctx, captured := container.captureScope(ctx, dep)
+	deferred.SetResolver(func() (any, error) {
+		// the wrapped dependency is resolved on its own so it does not take part in the resolution that injected the wrapper
+		_, instance, err := container.GetByType(captured, depType, name)
+		return instance, err
+	})
+
+	return ctx, wrapper.Elem(), true
+}
+
+// validateDeferred checks that the dependency wrapped by the deferred type is registered and can be assigned to the wrapper. The wrapped dependency is not
+// a child of the dependency because it is resolved when it is used
+// target: The description of the field or arg the wrapper is injected into, used in errors
+// missingAllowed: set if the wrapped dependency may be missing
+func (container *EctoContainer) validateDeferred(dep dependency.Dependency, t reflect.Type, name, target, funcName string, missingAllowed bool) []error {
+	depName := getDeferredName(t, name)
+	if _, ok := container.getContainerDependency(depName); ok {
+		return nil
+	}
+
+	depType := getDeferredType(t)
+	childDep, ok := container.findRegistration(depType, name)
+	if !ok {
+		if missingAllowed {
+			return nil
+		}
+		return []error{&ectoerrors.NotFoundError{Dependency: depName, Chain: []string{dep.GetName()}, Func: funcName}}
+	}
+
+	if !ectoreflect.CanCastType(depType, getInstanceType(childDep)) {
+		return []error{fmt.Errorf("%s on dependency '%s' has type '%s' which cannot be assigned dependency '%s' of type '%s'", target, dep.GetName(), t, childDep.GetName(), getInstanceType(childDep))}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	ectodependency "github.com/Gobusters/ectoinject/internal/dependency"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// Close stops the container if it was started, then disposes the instances created by the container and closes it. Scoped instances cached by the root scope are disposed first, then singletons are disposed in reverse dependency order.
+// Returns the errors of every failed stop and dispose
+func (container *EctoContainer) Close(ctx context.Context) error {
+	if !container.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	return errors.Join(container.Stop(ctx), container.root.Close(ctx), container.singletons.Close(ctx))
+}
+
+// getDisposeFunc gets the func used to dispose the instance when its scope is closed. Returns nil if the instance does not need to be disposed
+func (container *EctoContainer) getDisposeFunc(dep dependency.Dependency, val reflect.Value) func(context.Context) error {
+	if !val.IsValid() || dep.IsInstance() {
+		return nil // the container does not own the instance
+	}
+
+	if parent, _ := dep.GetResultOf(); parent != nil {
+		return nil // the field is disposed with its result struct
+	}
+
+	if ectodependency.IsResult(val.Type()) {
+		return container.getResultDisposeFunc(dep, val)
+	}
+
+	return container.getValueDisposeFunc(dep.GetName(), val)
+}
+
+// getCleanupDisposeFunc gets the func used to call the cleanup func returned by the constructor or provider of the dependency. A panic in the cleanup func is returned as an error
+func (container *EctoContainer) getCleanupDisposeFunc(dep dependency.Dependency, cleanup func()) func(context.Context) error {
+	return func(context.Context) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("failed to clean up dependency '%s': %v", dep.GetName(), r)
+			}
+		}()
+
+		cleanup()
+		return nil
+	}
+}
+
+// getValueDisposeFunc gets the func used to dispose the value with its dispose func or io.Closer. Returns nil if the value does not need to be disposed
+// name: The name of the dependency used in errors
+func (container *EctoContainer) getValueDisposeFunc(name string, val reflect.Value) func(context.Context) error {
+	if !val.IsValid() || ((val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && val.IsNil()) {
+		return nil
+	}
+
+	instance := ectoreflect.GetPointerOfValue(val)
+	if instance == nil {
+		return nil
+	}
+
+	if container.DisposeFuncName != "" {
+		method := reflect.ValueOf(instance).MethodByName(container.DisposeFuncName)
+		if method.IsValid() && isHookMethod(method.Type()) {
+			return func(ctx context.Context) error {
+				if err := callHookMethod(ctx, method); err != nil {
+					return fmt.Errorf("failed to dispose dependency '%s' with '%s' func: %w", name, container.DisposeFuncName, err)
+				}
+				return nil
+			}
+		}
+	}
+
+	closer, ok := instance.(io.Closer)
+	if !ok {
+		return nil
+	}
+
+	return func(context.Context) error {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to dispose dependency '%s': %w", name, err)
+		}
+		return nil
+	}
+}
+
+// getResultDisposeFunc gets the func used to dispose the fields of a result struct. Fields are disposed in reverse order
+func (container *EctoContainer) getResultDisposeFunc(dep dependency.Dependency, val reflect.Value) func(context.Context) error {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	var disposers []func(context.Context) error
+	for _, fieldDep := range ectodependency.NewResultDependencies(dep, container.InjectTagName) {
+		_, index := fieldDep.GetResultOf()
+		if dispose := container.getValueDisposeFunc(fieldDep.GetName(), val.FieldByIndex(index)); dispose != nil {
+			disposers = append(disposers, dispose)
+		}
+	}
+
+	if len(disposers) == 0 {
+		return nil
+	}
+
+	return func(ctx context.Context) error {
+		var errs []error
+		for i := len(disposers) - 1; i >= 0; i-- {
+			errs = append(errs, disposers[i](ctx))
+		}
+		return errors.Join(errs...)
+	}
+}
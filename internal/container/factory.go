@@ -0,0 +1,240 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// makeFactory creates the func of a factory dependency. The func builds a new product every time it is called, with the container and the scope of the context.
+// Singleton factories use the root scope of the container
+func (container *EctoContainer) makeFactory(ctx context.Context, dep dependency.Dependency) (context.Context, reflect.Value) {
+	factoryType := dep.GetDependencyType()
+	product := dep.GetProduct()
+
+	ctx, captured := container.captureScope(ctx, dep)
+	factory := reflect.MakeFunc(factoryType, func(args []reflect.Value) []reflect.Value {
+		val, err := container.buildProduct(captured, product, args)
+		return getFactoryResults(factoryType, val, err)
+	})
+
+	return ctx, factory
+}
+
+// buildProduct builds a new product of a factory and passes it through the decorators of its type. A cleanup func returned by the constructor is tracked by the scope of the context
+// args: The args the factory func was called with
+func (container *EctoContainer) buildProduct(ctx context.Context, product dependency.Dependency, args []reflect.Value) (reflect.Value, error) {
+	if container.closed.Load() {
+		return reflect.Value{}, fmt.Errorf("container '%s' is closed", container.ID)
+	}
+
+	chain := []dependency.Dependency{product}
+	res := &resolution{}
+
+	ctx, val, cleanup, err := container.createProduct(ctx, product, args, chain, res)
+	if err != nil {
+		return val, err
+	}
+
+	var dispose func(context.Context) error
+	if cleanup != nil {
+		// the cleanup func replaces disposing the product
+		dispose = container.getCleanupDisposeFunc(product, cleanup)
+	}
+
+	ctx, val, dispose, err = container.decorate(ctx, product, val, dispose, chain, res)
+	if err != nil || dispose == nil {
+		return val, err
+	}
+
+	_, s := container.scopeFromContext(ctx)
+	return val, s.Track(product, dispose)
+}
+
+// createProduct creates a new product of a factory. The args of the factory func are passed to the constructor args or the assisted fields of the product,
+// and every other dependency of the product is resolved from the container. Returns the cleanup func returned by the constructor
+// args: The args the factory func was called with
+func (container *EctoContainer) createProduct(ctx context.Context, product dependency.Dependency, args []reflect.Value, chain []dependency.Dependency, res *resolution) (context.Context, reflect.Value, func(), error) {
+	argTypes := make([]reflect.Type, len(args))
+	for i, arg := range args {
+		argTypes[i] = arg.Type()
+	}
+
+	if product.HasConstructor() {
+		constructor := product.GetConstructor()
+		bindings, err := bindAssistedArgs(argTypes, getFuncArgTypes(constructor.Type, 1))
+		if err != nil {
+			return ctx, reflect.Value{}, nil, fmt.Errorf("failed to create dependency '%s': %w", product.GetName(), err)
+		}
+
+		receiver, err := newConstructorReceiver(constructor)
+		if err != nil {
+			return ctx, reflect.Value{}, nil, err
+		}
+
+		// the first arg is the struct instance, followed by the args bound to the args of the factory func
+		preset := make([]reflect.Value, constructor.Type.NumIn())
+		preset[0] = receiver
+		for i, arg := range bindings {
+			if arg >= 0 {
+				preset[i+1] = args[arg]
+			}
+		}
+
+		ctx, val, cleanup, err := callDependencyFunc(ctx, container, product, constructor.Name, constructor.Func, preset, chain, res)
+		if err != nil {
+			return ctx, val, nil, err
+		}
+
+		return container.injectBuiltMethods(ctx, product, val, cleanup, chain, res)
+	}
+
+	fields, err := container.getInjectFields(product.GetDependencyValueType())
+	if err != nil {
+		return ctx, reflect.Value{}, nil, fmt.Errorf("dependency '%s' cannot be injected: %w", product.GetName(), err)
+	}
+
+	assisted := getAssistedFields(fields)
+	bindings, err := bindAssistedArgs(argTypes, getFieldTypes(assisted))
+	if err != nil {
+		return ctx, reflect.Value{}, nil, fmt.Errorf("failed to create dependency '%s': %w", product.GetName(), err)
+	}
+
+	val, err := ectoreflect.NewStructInstance(product.GetDependencyValueType())
+	if err != nil {
+		return ctx, reflect.Value{}, nil, err
+	}
+
+	for i, arg := range bindings {
+		if err := ectoreflect.SetField(val, assisted[i].StructField, args[arg]); err != nil {
+			return ctx, reflect.Value{}, nil, container.setFieldError(product, assisted[i].StructField, "", err)
+		}
+	}
+
+	ctx, err = container.setFields(ctx, product, val, fields, "", chain, res)
+	if err != nil {
+		return ctx, reflect.Value{}, nil, err
+	}
+
+	ctx, err = container.injectMethods(ctx, product, val, chain, res)
+	if err != nil {
+		return ctx, reflect.Value{}, nil, err
+	}
+
+	return ctx, val, nil, container.initDependency(ctx, val, chain, res)
+}
+
+// getFactoryResults creates the results of a factory func from the built product
+func getFactoryResults(factoryType reflect.Type, val reflect.Value, err error) []reflect.Value {
+	productType := factoryType.Out(0)
+
+	var product reflect.Value
+	if err == nil {
+		product, err = ectoreflect.CastType(productType, ectoreflect.GetPointerOfValue(val))
+	}
+
+	if err != nil {
+		return []reflect.Value{reflect.Zero(productType), reflect.ValueOf(&err).Elem()}
+	}
+
+	return []reflect.Value{product, reflect.Zero(errorType)}
+}
+
+// bindAssistedArgs binds each arg of a factory func to the first unbound target the arg can be assigned to, in order.
+// Returns the index of the arg bound to each target, or -1 for targets that are resolved from the container. Returns an error if an arg cannot be bound
+// args: The types of the args of the factory func
+// targets: The types of the constructor args or assisted fields of the product
+func bindAssistedArgs(args []reflect.Type, targets []reflect.Type) ([]int, error) {
+	bindings := make([]int, len(targets))
+	for i := range bindings {
+		bindings[i] = -1
+	}
+
+	for arg, argType := range args {
+		bound := false
+		for i, target := range targets {
+			if bindings[i] < 0 && argType.AssignableTo(target) {
+				bindings[i], bound = arg, true
+				break
+			}
+		}
+
+		if !bound {
+			return nil, fmt.Errorf("arg %d of type '%s' of the factory func cannot be passed to the constructor args or assisted fields", arg, argType)
+		}
+	}
+
+	return bindings, nil
+}
+
+// getFuncArgTypes gets the types of the args of the func starting at the first index
+func getFuncArgTypes(funcType reflect.Type, first int) []reflect.Type {
+	var types []reflect.Type
+	for i := first; i < funcType.NumIn(); i++ {
+		types = append(types, funcType.In(i))
+	}
+
+	return types
+}
+
+// getAssistedFields gets the fields set with the args of a factory func
+func getAssistedFields(fields []injectField) []injectField {
+	var assisted []injectField
+	for _, field := range fields {
+		if field.assisted {
+			assisted = append(assisted, field)
+		}
+	}
+
+	return assisted
+}
+
+// getFieldTypes gets the types of the fields
+func getFieldTypes(fields []injectField) []reflect.Type {
+	types := make([]reflect.Type, len(fields))
+	for i, field := range fields {
+		types[i] = field.Type
+	}
+
+	return types
+}
+
+// validateFactory checks that the args of the factory func can be passed to its product and that the dependencies of the product are registered.
+// The dependencies of the product are not children of the factory because they are resolved when the func is called
+func (container *EctoContainer) validateFactory(dep dependency.Dependency) []error {
+	product := dep.GetProduct()
+	argTypes := getFuncArgTypes(dep.GetDependencyType(), 0)
+
+	if product.HasConstructor() {
+		constructor := product.GetConstructor()
+		bindings, err := bindAssistedArgs(argTypes, getFuncArgTypes(constructor.Type, 1))
+		if err != nil {
+			return []error{fmt.Errorf("factory '%s' cannot create dependency '%s': %w", dep.GetName(), product.GetName(), err)}
+		}
+
+		assisted := make(map[int]bool)
+		for i, arg := range bindings {
+			if arg >= 0 {
+				assisted[i+1] = true
+			}
+		}
+
+		_, errs := container.validateArgs(product, constructor.Type, 1, constructor.Name, assisted)
+		return errs
+	}
+
+	fields, err := container.getInjectFields(product.GetDependencyValueType())
+	if err != nil {
+		return []error{fmt.Errorf("dependency '%s' cannot be injected: %w", product.GetName(), err)}
+	}
+
+	if _, err := bindAssistedArgs(argTypes, getFieldTypes(getAssistedFields(fields))); err != nil {
+		return []error{fmt.Errorf("factory '%s' cannot create dependency '%s': %w", dep.GetName(), product.GetName(), err)}
+	}
+
+	_, errs := container.validateFields(product, fields, "")
+	return errs
+}
@@ -0,0 +1,207 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Gobusters/ectoinject/dependency"
+
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+var inType = reflect.TypeOf(dependency.In{})
+
+// injectField is a struct field the container injects a dependency into
+type injectField struct {
+	reflect.StructField
+	name     string        // the name in the inject tag. Empty if the dependency is matched by the type of the field
+	optional bool          // set if the field is left empty when its dependency is not registered
+	required bool          // set if the field fails the resolution when its dependency is not registered, even if missing dependencies are allowed
+	assisted bool          // set if the field is set with an arg of a factory func instead of a dependency
+	inline   bool          // set if the fields of the struct of the field are injected instead of the field itself
+	fields   []injectField // the fields of the struct of an inline field
+}
+
+// parseInjectTag parses an inject tag such as `cache,optional` into the field. The name comes first and is followed by the options.
+// Returns an error for unknown options
+func parseInjectTag(field *injectField, tag string) error {
+	name, options, _ := strings.Cut(tag, ",")
+	field.name = name
+	if options == "" {
+		return nil
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		switch option {
+		case "optional":
+			field.optional = true
+		case "required":
+			field.required = true
+		case "assisted":
+			field.assisted = true
+		case "inline":
+			field.inline = true
+		default:
+			return fmt.Errorf("field '%s' has unknown option '%s' in inject tag '%s'. Options must be one of [optional required assisted inline]", field.Name, option, tag)
+		}
+	}
+
+	if field.optional && field.required {
+		return fmt.Errorf("field '%s' cannot be both optional and required in inject tag '%s'", field.Name, tag)
+	}
+
+	if field.inline && (field.name != "" || field.optional || field.required || field.assisted) {
+		return fmt.Errorf("field '%s' cannot have a name or other options with the inline option in inject tag '%s'", field.Name, tag)
+	}
+
+	return nil
+}
+
+// isMissingAllowed checks if the field may be left empty when its dependency is not registered
+// funcName: The name of the func the struct is passed to when it is a parameter object. Empty when the struct is a dependency
+func (container *EctoContainer) isMissingAllowed(field injectField, funcName string) bool {
+	if field.optional || field.required {
+		return field.optional
+	}
+
+	return funcName == "" && container.AllowMissingDependencies
+}
+
+// dependencyName gets the name of the dependency injected into the field. Fields without a name are named after their type
+func (field injectField) dependencyName() string {
+	if field.name == "" {
+		return ectoreflect.GetReflectTypeName(field.Type)
+	}
+
+	return field.name
+}
+
+// getInjectFields gets the fields of the struct type that the container injects dependencies into. Returns an error if an inject tag is invalid
+// t: The struct type
+func (container *EctoContainer) getInjectFields(t reflect.Type) ([]injectField, error) {
+	return container.getStructFields(t, map[reflect.Type]bool{t: true})
+}
+
+// getStructFields gets the fields of the struct type that the container injects dependencies into, walking into inline fields
+// t: The struct type
+// path: The struct types being walked. Used to stop structs from inlining themselves
+func (container *EctoContainer) getStructFields(t reflect.Type, path map[reflect.Type]bool) ([]injectField, error) {
+	var fields []injectField
+	val := reflect.New(t).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(container.InjectTagName)
+
+		if (tag == "" && container.RequireInjectTag) || tag == "-" {
+			continue // skip this field
+		}
+
+		canSet := val.Field(i).CanSet()
+
+		if !canSet && !container.AllowUnsafeDependencies {
+			continue // skip this field
+		}
+
+		f := injectField{StructField: field}
+		if err := parseInjectTag(&f, tag); err != nil {
+			return nil, err
+		}
+
+		if err := container.setInlineFields(&f, path); err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// setInlineFields gets the fields of the struct of an inline field. Fields tagged with the inline option are inlined, as are embedded structs that are not registered.
+// Returns an error if a field tagged with the inline option is not a struct
+// path: The struct types being walked. Used to stop structs from inlining themselves
+func (container *EctoContainer) setInlineFields(field *injectField, path map[reflect.Type]bool) error {
+	structType := field.Type
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	isStruct := structType.Kind() == reflect.Struct
+	if !field.inline {
+		// embedded structs are inlined when they are not a dependency themselves
+		field.inline = field.Anonymous && isStruct && field.name == "" && !field.optional && !field.required && !field.assisted &&
+			!path[structType] && !isDeferred(field.Type) && !isParamObject(structType)
+		if field.inline {
+			_, registered := container.findRegistration(field.Type, "")
+			field.inline = !registered
+		}
+
+		if !field.inline {
+			return nil
+		}
+	}
+
+	if !isStruct {
+		return fmt.Errorf("field '%s' has the inline option but type '%s' is not a struct", field.Name, field.Type)
+	}
+
+	if path[structType] {
+		return fmt.Errorf("field '%s' cannot inline type '%s' into itself", field.Name, field.Type)
+	}
+
+	path[structType] = true
+	defer delete(path, structType)
+
+	fields, err := container.getStructFields(structType, path)
+	if err != nil {
+		return fmt.Errorf("field '%s' cannot be inlined: %w", field.Name, err)
+	}
+
+	field.fields = fields
+	return nil
+}
+
+// isParamObject checks if the type is a struct that embeds dependency.In
+// t: The type of the arg
+func isParamObject(t reflect.Type) bool {
+	return ectoreflect.Embeds(t, inType)
+}
+
+// getParamFields gets the fields of a parameter object. Every field is injected except the embedded dependency.In, context.Context fields and fields tagged with "-".
+// Returns an error if an inject tag is invalid
+// t: The struct type of the parameter object
+func (container *EctoContainer) getParamFields(t reflect.Type) ([]injectField, error) {
+	var fields []injectField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if (field.Anonymous && field.Type == inType) || field.Type == contextType {
+			continue // the marker is not a dependency and the context is set by the container
+		}
+
+		tag := field.Tag.Get(container.InjectTagName)
+		if tag == "-" {
+			continue // skip this field
+		}
+
+		if !field.IsExported() && !container.AllowUnsafeDependencies {
+			continue // skip this field
+		}
+
+		f := injectField{StructField: field}
+		if err := parseInjectTag(&f, tag); err != nil {
+			return nil, err
+		}
+
+		if f.inline {
+			// the fields of an inline struct follow the rules of dependency fields
+			if err := container.setInlineFields(&f, map[reflect.Type]bool{t: true}); err != nil {
+				return nil, err
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	ectodependency "github.com/Gobusters/ectoinject/internal/dependency"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// Inject sets the dependencies of a struct created outside the container. The fields of the struct are injected with the same rules as the fields of
+// a dependency built by the container. Returns the context with the scoped dependencies created while injecting the struct
+// ctx: The context to use
+// target: A pointer to the struct
+func (container *EctoContainer) Inject(ctx context.Context, target any) (context.Context, error) {
+	if container.closed.Load() {
+		return ctx, fmt.Errorf("container '%s' is closed", container.ID)
+	}
+
+	val := reflect.ValueOf(target)
+	if target == nil || val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return ctx, fmt.Errorf("target must be a pointer to a struct but is %T", target)
+	}
+
+	// the struct is described by a dependency that is never registered so it can be part of the dependency chain
+	dep := ectodependency.NewTargetDependency(val.Elem().Type())
+
+	return container.setDependencies(ctx, dep, val, []dependency.Dependency{dep}, resolutionFromContext(ctx))
+}
+
+// Invoke calls the func with its args resolved from the container the same way as the args of a constructor. Returns the context with the scoped dependencies
+// created while resolving the args, the results of the func and an error. If the last result of the func is an error, it is returned as the error instead of a result
+// ctx: The context to use
+// fn: The func to call
+func (container *EctoContainer) Invoke(ctx context.Context, fn any) (context.Context, []any, error) {
+	if container.closed.Load() {
+		return ctx, nil, fmt.Errorf("container '%s' is closed", container.ID)
+	}
+
+	fnVal := reflect.ValueOf(fn)
+	if fn == nil || fnVal.Kind() != reflect.Func || fnVal.IsNil() {
+		return ctx, nil, fmt.Errorf("fn must be a func but is %T", fn)
+	}
+
+	fnType := fnVal.Type()
+	if fnType.IsVariadic() {
+		return ctx, nil, fmt.Errorf("func '%s' cannot be variadic", ectoreflect.GetFuncName(fnVal))
+	}
+
+	// the func is described by a dependency that is never registered so it can be part of the dependency chain
+	dep := ectodependency.NewInvokeDependency(fnVal)
+	chain := []dependency.Dependency{dep}
+	res := resolutionFromContext(ctx)
+
+	args := make([]reflect.Value, fnType.NumIn())
+	for i := range args {
+		var err error
+		ctx, args[i], err = container.getArg(ctx, dep, dep.GetName(), fnType.In(i), chain, res)
+		if err != nil {
+			return ctx, nil, err
+		}
+	}
+
+	results := fnVal.Call(args)
+
+	// the last result is returned as the error of the func
+	var err error
+	if n := fnType.NumOut(); n > 0 && fnType.Out(n-1) == errorType {
+		err, _ = results[n-1].Interface().(error)
+		results = results[:n-1]
+	}
+
+	values := make([]any, len(results))
+	for i, result := range results {
+		values[i] = result.Interface()
+	}
+
+	return ctx, values, err
+}
@@ -0,0 +1,189 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// stopHook stops a started singleton
+type stopHook struct {
+	name string                          // the name of the dependency
+	stop func(ctx context.Context) error // the stop hook of the instance
+}
+
+// Start builds every singleton and starts them in dependency order. A singleton is only started after the singletons it depends on have started.
+// If a singleton fails to start, the singletons that have already started are stopped in reverse order
+func (container *EctoContainer) Start(ctx context.Context) error {
+	container.lifecycleMu.Lock()
+	defer container.lifecycleMu.Unlock()
+
+	if container.closed.Load() {
+		return fmt.Errorf("container '%s' is closed", container.ID)
+	}
+
+	if container.running {
+		return fmt.Errorf("container '%s' has already been started", container.ID)
+	}
+
+	// build every singleton so the whole graph is started
+	err := container.WarmUp(ctx)
+	if err != nil {
+		return err
+	}
+
+	// singletons are cached in the order they finished building, which is always after their dependencies
+	var started []stopHook
+	for _, instance := range container.singletons.Instances() {
+		dep := instance.Dependency()
+		val, _ := instance.Value()
+		if dep.IsInstance() || !val.IsValid() {
+			continue // the container does not own the instance
+		}
+
+		obj := ectoreflect.GetPointerOfValue(val)
+		if start := container.getStartHook(obj); start != nil {
+			if err := start(ctx); err != nil {
+				err = fmt.Errorf("failed to start dependency '%s': %w", dep.GetName(), err)
+				// roll back the singletons that have already started
+				return errors.Join(err, stopAll(ctx, started))
+			}
+		}
+
+		if stop := container.getStopHook(obj); stop != nil {
+			started = append(started, stopHook{name: dep.GetName(), stop: stop})
+		}
+	}
+
+	container.running = true
+	container.stopHooks = started
+
+	return nil
+}
+
+// Stop stops the started singletons in the reverse order they were started. Returns the errors of every failed stop
+func (container *EctoContainer) Stop(ctx context.Context) error {
+	container.lifecycleMu.Lock()
+	defer container.lifecycleMu.Unlock()
+
+	if !container.running {
+		return nil
+	}
+
+	hooks := container.stopHooks
+	container.running = false
+	container.stopHooks = nil
+
+	return stopAll(ctx, hooks)
+}
+
+// getRegistrations gets every registered dependency sorted by name, then by type
+func (container *EctoContainer) getRegistrations() []dependency.Dependency {
+	container.mu.RLock()
+	deps := make([]dependency.Dependency, 0, len(container.container))
+	for _, dep := range container.container {
+		deps = append(deps, dep)
+	}
+	container.mu.RUnlock()
+
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].GetName() != deps[j].GetName() {
+			return deps[i].GetName() < deps[j].GetName()
+		}
+		// distinct types can have the same name
+		return deps[i].GetDependencyType().String() < deps[j].GetDependencyType().String()
+	})
+
+	return deps
+}
+
+// stopAll calls the stop hooks in reverse order
+func stopAll(ctx context.Context, hooks []stopHook) error {
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop dependency '%s': %w", hooks[i].name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// getStartHook gets the start hook of the instance. Uses the method named StartFuncName if it is configured, otherwise ectocontainer.Starter
+func (container *EctoContainer) getStartHook(instance any) func(context.Context) error {
+	if container.StartFuncName != "" {
+		return getMethodHook(instance, container.StartFuncName)
+	}
+
+	starter, ok := instance.(ectocontainer.Starter)
+	if !ok {
+		return nil
+	}
+
+	return starter.Start
+}
+
+// getStopHook gets the stop hook of the instance. Uses the method named StopFuncName if it is configured, otherwise ectocontainer.Stopper
+func (container *EctoContainer) getStopHook(instance any) func(context.Context) error {
+	if container.StopFuncName != "" {
+		return getMethodHook(instance, container.StopFuncName)
+	}
+
+	stopper, ok := instance.(ectocontainer.Stopper)
+	if !ok {
+		return nil
+	}
+
+	return stopper.Stop
+}
+
+// getMethodHook gets a hook that calls the method with the provided name. Returns nil if the instance does not have the method or the method is not a hook
+func getMethodHook(instance any, funcName string) func(context.Context) error {
+	method := reflect.ValueOf(instance).MethodByName(funcName)
+	if !method.IsValid() || !isHookMethod(method.Type()) {
+		return nil
+	}
+
+	return func(ctx context.Context) error {
+		if err := callHookMethod(ctx, method); err != nil {
+			return fmt.Errorf("'%s' func failed: %w", funcName, err)
+		}
+		return nil
+	}
+}
+
+// isHookMethod checks if the method can be called as a hook. A hook may only accept context.Context args and may only return an error.
+// Methods with the name of a hook but another signature are unrelated to the container and are not called
+func isHookMethod(methodType reflect.Type) bool {
+	for i := 0; i < methodType.NumIn(); i++ {
+		if methodType.In(i) != contextType {
+			return false
+		}
+	}
+
+	return methodType.NumOut() == 0 || (methodType.NumOut() == 1 && methodType.Out(0) == errorType)
+}
+
+// callHookMethod calls a hook method. The method may accept a context.Context and may return an error
+func callHookMethod(ctx context.Context, method reflect.Value) error {
+	args := make([]reflect.Value, method.Type().NumIn())
+	for i := range args {
+		args[i] = reflect.ValueOf(ctx)
+	}
+
+	result := method.Call(args)
+	if len(result) == 0 || result[0].IsNil() {
+		return nil
+	}
+
+	return result[0].Interface().(error)
+}
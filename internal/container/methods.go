@@ -0,0 +1,101 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// getInjectMethods gets the methods of the type whose name starts with InjectMethodPrefix, in name order. The constructor is never an inject method
+func (container *EctoContainer) getInjectMethods(t reflect.Type) []reflect.Method {
+	if container.InjectMethodPrefix == "" || t.Kind() == reflect.Interface {
+		return nil
+	}
+
+	var methods []reflect.Method
+	for i := 0; i < t.NumMethod(); i++ {
+		method := t.Method(i)
+		if strings.HasPrefix(method.Name, container.InjectMethodPrefix) && method.Name != container.ConstructorFuncName {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
+
+// checkInjectMethod checks that the inject method returns nothing or an error
+func checkInjectMethod(dep dependency.Dependency, method reflect.Method) error {
+	if method.Type.IsVariadic() {
+		return fmt.Errorf("method '%s' of dependency '%s' cannot be variadic", method.Name, dep.GetName())
+	}
+
+	if method.Type.NumOut() > 1 || (method.Type.NumOut() == 1 && method.Type.Out(0) != errorType) {
+		return fmt.Errorf("method '%s' of dependency '%s' must return nothing or an error", method.Name, dep.GetName())
+	}
+
+	return nil
+}
+
+// injectMethods calls the inject methods of a new instance of the dependency with their args resolved from the container the same way as the args of a constructor
+// val: The instance of the dependency
+func (container *EctoContainer) injectMethods(ctx context.Context, dep dependency.Dependency, val reflect.Value, chain []dependency.Dependency, res *resolution) (context.Context, error) {
+	if container.InjectMethodPrefix == "" || !val.IsValid() {
+		return ctx, nil
+	}
+
+	instance := reflect.ValueOf(ectoreflect.GetPointerOfValue(val))
+	if instance.Kind() == reflect.Ptr && instance.IsNil() {
+		return ctx, nil
+	}
+
+	for _, method := range container.getInjectMethods(instance.Type()) {
+		err := checkInjectMethod(dep, method)
+		if err != nil {
+			return ctx, err
+		}
+
+		// the first arg is the instance
+		args := make([]reflect.Value, method.Type.NumIn())
+		args[0] = instance
+		for i := 1; i < len(args); i++ {
+			ctx, args[i], err = container.getArg(ctx, dep, method.Name, method.Type.In(i), chain, res)
+			if err != nil {
+				return ctx, err
+			}
+		}
+
+		results := method.Func.Call(args)
+		if len(results) == 1 && !results[0].IsNil() {
+			return ctx, fmt.Errorf("failed to call method '%s' of dependency '%s'. Dependency chain: %s: %w", method.Name, dep.GetName(), formatChain(chain), results[0].Interface().(error))
+		}
+	}
+
+	return ctx, nil
+}
+
+// validateMethods checks the args of the inject methods of the dependency. Returns the dependencies of the methods and the problems found
+func (container *EctoContainer) validateMethods(dep dependency.Dependency) ([]dependency.Dependency, []error) {
+	if parent, _ := dep.GetResultOf(); dep.GetInstanceFunc() != nil || dep.GetProduct() != nil || parent != nil {
+		return nil, nil // the container does not build the instance
+	}
+
+	var children []dependency.Dependency
+	var errs []error
+	for _, method := range container.getInjectMethods(getInstanceType(dep)) {
+		if err := checkInjectMethod(dep, method); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// skip the first arg, it is the instance
+		methodChildren, methodErrs := container.validateArgs(dep, method.Type, 1, method.Name, nil)
+		children = append(children, methodChildren...)
+		errs = append(errs, methodErrs...)
+	}
+
+	return children, errs
+}
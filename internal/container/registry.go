@@ -0,0 +1,100 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// registrationKey identifies a registration. Distinct types never share a key, even when their names are formatted the same
+type registrationKey struct {
+	Type reflect.Type // the type the dependency is registered as
+	Name string       // the name the dependency is registered with. Empty for unnamed dependencies
+}
+
+// String formats the key for error messages
+func (key registrationKey) String() string {
+	if key.Name == "" {
+		return key.Type.String()
+	}
+
+	return fmt.Sprintf("%s (%s)", key.Name, key.Type)
+}
+
+// keyOf gets the key the dependency is registered with
+func keyOf(dep dependency.Dependency) registrationKey {
+	key := registrationKey{Type: dep.GetDependencyType()}
+	if dep.IsNamed() {
+		key.Name = dep.GetName()
+	}
+
+	return key
+}
+
+// findRegistration gets the registration for a dependency of type t. Registrations are matched by type and name, then by the pointer or element type of t,
+// then by the name alone if only one registration has the name. Unnamed dependencies are named after their type
+// t: The type of the field or arg the dependency is injected into
+// name: (optional) The name of the dependency
+func (container *EctoContainer) findRegistration(t reflect.Type, name string) (dependency.Dependency, bool) {
+	container.mu.RLock()
+	defer container.mu.RUnlock()
+
+	if dep, ok := container.container[registrationKey{Type: t, Name: name}]; ok {
+		return dep, true
+	}
+
+	// fields of type *Foo can be injected with a Foo and fields of type Foo with a *Foo
+	if other := variantOf(t); other != nil {
+		if dep, ok := container.container[registrationKey{Type: other, Name: name}]; ok {
+			return dep, true
+		}
+	}
+
+	if name == "" {
+		name = ectoreflect.GetReflectTypeName(t)
+	}
+
+	keys := container.names[name]
+	if len(keys) != 1 {
+		return nil, false
+	}
+
+	return container.container[keys[0]], true
+}
+
+// variantOf gets the pointer type of t or the element type of a pointer type. Returns nil for interfaces, which do not have a variant
+func variantOf(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return t.Elem()
+	case reflect.Interface:
+		return nil
+	default:
+		return reflect.PointerTo(t)
+	}
+}
+
+// getRegistration gets the registered dependency with the provided name. Unnamed dependencies are named after their type.
+// Returns an error if more than one registration has the name
+func (container *EctoContainer) getRegistration(name string) (dependency.Dependency, bool, error) {
+	container.mu.RLock()
+	defer container.mu.RUnlock()
+
+	keys := container.names[name]
+	switch len(keys) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return container.container[keys[0]], true, nil
+	}
+
+	types := make([]string, len(keys))
+	for i, key := range keys {
+		types[i] = key.Type.String()
+	}
+
+	return nil, false, fmt.Errorf("dependency name '%s' is registered for more than one type: %s. Get the dependency by its type", name, strings.Join(types, ", "))
+}
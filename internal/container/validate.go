@@ -0,0 +1,327 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	"github.com/Gobusters/ectoinject/ectoerrors"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+	"github.com/Gobusters/ectoinject/lifecycles"
+)
+
+// Validate checks every registration without building any instance. Struct fields, inject tags and constructor args are inspected with reflection to find
+// missing dependencies, circular dependencies, captive dependencies and dependencies that cannot be assigned. Returns every problem found
+// keys: (optional) Keys that must be registered with a type that can be assigned to the type of the key
+func (container *EctoContainer) Validate(keys ...dependency.Key) error {
+	deps := container.getRegistrations()
+
+	// the dependencies of each registration
+	graph := make(map[registrationKey][]dependency.Dependency, len(deps))
+	var errs []error
+	for _, dep := range deps {
+		children, depErrs := container.validateDependency(dep)
+		graph[keyOf(dep)] = children
+		errs = append(errs, depErrs...)
+	}
+
+	for _, key := range keys {
+		errs = append(errs, container.validateKey(key)...)
+	}
+
+	errs = append(errs, validateCycles(deps, graph)...)
+
+	if !container.AllowCaptiveDependencies {
+		errs = append(errs, validateCaptives(deps, graph)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateKey checks that the key is registered with a type that can be assigned to the type of the key
+func (container *EctoContainer) validateKey(key dependency.Key) []error {
+	name := key.Name()
+	if name == "" {
+		name = ectoreflect.GetReflectTypeName(key.Type())
+	}
+
+	if _, ok := container.getContainerDependency(name); ok {
+		return nil
+	}
+
+	dep, ok := container.findRegistration(key.Type(), key.Name())
+	if !ok {
+		return []error{&ectoerrors.NotFoundError{Dependency: name}}
+	}
+
+	if !ectoreflect.CanCastType(key.Type(), dep.GetDependencyType()) {
+		return []error{fmt.Errorf("key '%s' has type '%s' which cannot be assigned dependency '%s' of type '%s'", name, key.Type(), dep.GetName(), dep.GetDependencyType())}
+	}
+
+	return nil
+}
+
+// validateDependency checks how the dependency is built, injected and decorated. Returns its dependencies and the problems found
+func (container *EctoContainer) validateDependency(dep dependency.Dependency) ([]dependency.Dependency, []error) {
+	children, errs := container.validateInstance(dep)
+	methodChildren, methodErrs := container.validateMethods(dep)
+	decoratorChildren, decoratorErrs := container.validateDecorators(dep)
+
+	children = append(append(children, methodChildren...), decoratorChildren...)
+	return children, append(append(errs, methodErrs...), decoratorErrs...)
+}
+
+// validateInstance checks the struct fields or constructor args of the dependency. Returns its dependencies and the problems found
+func (container *EctoContainer) validateInstance(dep dependency.Dependency) ([]dependency.Dependency, []error) {
+	if parent, _ := dep.GetResultOf(); parent != nil {
+		return []dependency.Dependency{parent}, nil // the field is taken from the result struct
+	}
+
+	if dep.GetInstanceFunc() != nil {
+		return nil, nil // the dependencies of an instance func cannot be inspected
+	}
+
+	if dep.GetProduct() != nil {
+		return nil, container.validateFactory(dep)
+	}
+
+	if dep.HasProvider() {
+		return container.validateArgs(dep, dep.GetProvider().Type(), 0, dep.GetProviderName(), nil)
+	}
+
+	if dep.HasConstructor() {
+		return container.validateConstructor(dep)
+	}
+
+	if container.RequireConstructor {
+		return nil, nil // the dependency is not built
+	}
+
+	valueType := dep.GetDependencyValueType()
+	if valueType.Kind() != reflect.Struct {
+		return nil, []error{fmt.Errorf("dependency '%s' has type '%s' which is not a struct", dep.GetName(), valueType.Name())}
+	}
+
+	fields, err := container.getInjectFields(valueType)
+	if err != nil {
+		return nil, []error{fmt.Errorf("dependency '%s' cannot be injected: %w", dep.GetName(), err)}
+	}
+
+	return container.validateFields(dep, fields, "")
+}
+
+// validateFields checks the fields of a dependency or a parameter object. Returns the dependencies of the fields and the problems found
+// funcName: The name of the func the struct is passed to when it is a parameter object. Empty when the struct is a dependency
+func (container *EctoContainer) validateFields(dep dependency.Dependency, fields []injectField, funcName string) ([]dependency.Dependency, []error) {
+	var children []dependency.Dependency
+	var errs []error
+	for _, field := range fields {
+		if field.assisted {
+			continue // set by the factory func that creates the dependency
+		}
+
+		if field.inline {
+			inlineChildren, inlineErrs := container.validateFields(dep, field.fields, funcName)
+			children = append(children, inlineChildren...)
+			errs = append(errs, inlineErrs...)
+			continue
+		}
+
+		if _, ok := container.getContainerDependency(field.dependencyName()); ok {
+			continue
+		}
+
+		if isDeferred(field.Type) {
+			errs = append(errs, container.validateDeferred(dep, field.Type, field.name, fmt.Sprintf("field '%s'", field.Name), funcName, container.isMissingAllowed(field, funcName))...)
+			continue
+		}
+
+		childDep, ok := container.findRegistration(field.Type, field.name)
+		if !ok && field.name == "" && container.hasCollection(field.Type) {
+			collectionChildren, collectionErrs := container.validateCollection(dep, field.Type, fmt.Sprintf("field '%s'", field.Name))
+			children = append(children, collectionChildren...)
+			errs = append(errs, collectionErrs...)
+			continue
+		}
+
+		if !ok {
+			if !container.isMissingAllowed(field, funcName) {
+				errs = append(errs, &ectoerrors.NotFoundError{Dependency: field.dependencyName(), Chain: []string{dep.GetName()}, Func: funcName})
+			}
+			continue
+		}
+
+		if !ectoreflect.CanCastType(field.Type, getInstanceType(childDep)) {
+			errs = append(errs, fmt.Errorf("field '%s' on dependency '%s' has type '%s' which cannot be assigned dependency '%s' of type '%s'", field.Name, dep.GetName(), field.Type, childDep.GetName(), getInstanceType(childDep)))
+		}
+
+		children = append(children, childDep)
+	}
+
+	return children, errs
+}
+
+// validateConstructor checks the args of the constructor of the dependency. Returns its dependencies and the problems found
+func (container *EctoContainer) validateConstructor(dep dependency.Dependency) ([]dependency.Dependency, []error) {
+	constructor := dep.GetConstructor()
+
+	// skip the first arg, it is the struct instance
+	return container.validateArgs(dep, constructor.Type, 1, constructor.Name, nil)
+}
+
+// validateArgs checks the args of a func called by the container. Returns the dependencies of the args and the problems found
+// funcType: The type of the func
+// first: The index of the first arg resolved by the container
+// funcName: The name of the func
+// preset: (optional) The indexes of the args that are not resolved from the container, such as the args set with the args of a factory func
+func (container *EctoContainer) validateArgs(dep dependency.Dependency, funcType reflect.Type, first int, funcName string, preset map[int]bool) ([]dependency.Dependency, []error) {
+	var children []dependency.Dependency
+	var errs []error
+	for i := first; i < funcType.NumIn(); i++ {
+		if preset[i] {
+			continue
+		}
+
+		argType := funcType.In(i)
+		paramTypeName := ectoreflect.GetReflectTypeName(argType)
+		if paramTypeName == "context.Context" {
+			continue
+		}
+
+		if isParamObject(argType) {
+			paramType := argType
+			if paramType.Kind() == reflect.Ptr {
+				paramType = paramType.Elem()
+			}
+
+			fields, err := container.getParamFields(paramType)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("arg of '%s' func of dependency '%s' cannot be injected: %w", funcName, dep.GetName(), err))
+				continue
+			}
+
+			fieldChildren, fieldErrs := container.validateFields(dep, fields, funcName)
+			children = append(children, fieldChildren...)
+			errs = append(errs, fieldErrs...)
+			continue
+		}
+
+		if _, ok := container.getContainerDependency(paramTypeName); ok {
+			continue
+		}
+
+		if isDeferred(argType) {
+			errs = append(errs, container.validateDeferred(dep, argType, "", fmt.Sprintf("arg %d of '%s' func", i, funcName), funcName, false)...)
+			continue
+		}
+
+		childDep, ok := container.findRegistration(argType, "")
+		if !ok && container.hasCollection(argType) {
+			collectionChildren, collectionErrs := container.validateCollection(dep, argType, fmt.Sprintf("arg %d of '%s' func", i, funcName))
+			children = append(children, collectionChildren...)
+			errs = append(errs, collectionErrs...)
+			continue
+		}
+
+		if !ok {
+			errs = append(errs, &ectoerrors.NotFoundError{Dependency: paramTypeName, Chain: []string{dep.GetName()}, Func: funcName})
+			continue
+		}
+
+		if !ectoreflect.CanCastType(argType, getInstanceType(childDep)) {
+			errs = append(errs, fmt.Errorf("arg %d of '%s' func on dependency '%s' has type '%s' which cannot be assigned dependency '%s' of type '%s'", i, funcName, dep.GetName(), argType, childDep.GetName(), getInstanceType(childDep)))
+		}
+
+		children = append(children, childDep)
+	}
+
+	return children, errs
+}
+
+// getInstanceType gets the type of the instances created for the dependency without creating an instance
+func getInstanceType(dep dependency.Dependency) reflect.Type {
+	if parent, _ := dep.GetResultOf(); dep.GetInstanceFunc() != nil || dep.HasProvider() || dep.GetProduct() != nil || parent != nil {
+		return dep.GetDependencyType()
+	}
+
+	if dep.HasConstructor() && dep.GetConstructor().Type.NumOut() > 0 {
+		return dep.GetConstructor().Type.Out(0)
+	}
+
+	// field injected structs are injected by their address
+	return reflect.PointerTo(dep.GetDependencyValueType())
+}
+
+// validateCycles finds every circular dependency in the graph
+func validateCycles(deps []dependency.Dependency, graph map[registrationKey][]dependency.Dependency) []error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := make(map[registrationKey]int, len(deps))
+	var path []string
+	var errs []error
+
+	var visit func(dep dependency.Dependency)
+	visit = func(dep dependency.Dependency) {
+		key := keyOf(dep)
+		state[key] = visiting
+		path = append(path, dep.GetName())
+
+		for _, child := range graph[key] {
+			switch state[keyOf(child)] {
+			case visiting:
+				errs = append(errs, circularDependencyError(child.GetName(), path))
+			case 0:
+				visit(child)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[key] = visited
+	}
+
+	for _, dep := range deps {
+		if state[keyOf(dep)] == 0 {
+			visit(dep)
+		}
+	}
+
+	return errs
+}
+
+// validateCaptives finds every dependency that would be held captive by a dependency with a longer lifecycle
+func validateCaptives(deps []dependency.Dependency, graph map[registrationKey][]dependency.Dependency) []error {
+	var errs []error
+	for _, parent := range deps {
+		if parent.GetLifecycle() == lifecycles.Transient {
+			continue
+		}
+
+		// walk every dependency reachable from the parent, keeping the path to each of them
+		seen := map[registrationKey]bool{keyOf(parent): true}
+		queue := [][]dependency.Dependency{{parent}}
+		for len(queue) > 0 {
+			path := queue[0]
+			queue = queue[1:]
+
+			for _, child := range graph[keyOf(path[len(path)-1])] {
+				if seen[keyOf(child)] {
+					continue
+				}
+				seen[keyOf(child)] = true
+
+				childPath := append(append([]dependency.Dependency{}, path...), child)
+				if isCaptive(parent, child) {
+					errs = append(errs, captiveDependencyError(parent, child, getChainNames(childPath)))
+				}
+
+				queue = append(queue, childPath)
+			}
+		}
+	}
+
+	return errs
+}
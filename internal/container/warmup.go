@@ -0,0 +1,169 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/Gobusters/ectoinject/dependency"
+	"github.com/Gobusters/ectoinject/lifecycles"
+)
+
+// buildResult is the result of building a singleton during WarmUp
+type buildResult struct {
+	dep dependency.Dependency // the singleton
+	err error                 // the error returned while building the singleton
+}
+
+// WarmUp builds every singleton so the first call to Get does not pay for building them. A singleton is built once the singletons it depends on have been built,
+// so independent branches of the dependency graph are built in parallel, up to WarmUpConcurrency at a time. Returns an error for every singleton that failed to build
+func (container *EctoContainer) WarmUp(ctx context.Context) error {
+	if container.closed.Load() {
+		return fmt.Errorf("container '%s' is closed", container.ID)
+	}
+
+	var singletons []dependency.Dependency
+	lookup := make(map[registrationKey]dependency.Dependency)
+	for _, dep := range container.getRegistrations() {
+		if dep.GetLifecycle() == lifecycles.Singleton {
+			singletons = append(singletons, dep)
+			lookup[keyOf(dep)] = dep
+		}
+	}
+
+	// the number of dependencies each singleton is waiting on and the singletons waiting on each singleton
+	waiting := make(map[registrationKey]int, len(singletons))
+	dependents := make(map[registrationKey][]registrationKey, len(singletons))
+	for _, dep := range singletons {
+		for _, child := range container.getSingletonDependencies(dep) {
+			waiting[keyOf(dep)]++
+			dependents[child] = append(dependents[child], keyOf(dep))
+		}
+	}
+
+	var ready []registrationKey
+	for _, dep := range singletons {
+		if waiting[keyOf(dep)] == 0 {
+			ready = append(ready, keyOf(dep))
+		}
+	}
+
+	limit := container.WarmUpConcurrency
+	if limit <= 0 {
+		limit = runtime.GOMAXPROCS(0)
+	}
+
+	results := make(chan buildResult)
+	errs := make(map[registrationKey]error)
+	started := make(map[registrationKey]bool, len(singletons))
+	running, finished := 0, 0
+
+	// finish records the result of a singleton and queues the singletons that were waiting on it
+	var finish func(result buildResult)
+	finish = func(result buildResult) {
+		finished++
+		key := keyOf(result.dep)
+		if result.err != nil {
+			errs[key] = result.err
+		}
+
+		for _, dependent := range dependents[key] {
+			if started[dependent] {
+				continue
+			}
+
+			if result.err != nil {
+				// building the dependent would only build the failed singleton again
+				started[dependent] = true
+				finish(buildResult{dep: lookup[dependent], err: fmt.Errorf("dependency '%s' failed to build", result.dep.GetName())})
+				continue
+			}
+
+			waiting[dependent]--
+			if waiting[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	for finished < len(singletons) {
+		for len(ready) > 0 && running < limit {
+			key := ready[0]
+			ready = ready[1:]
+			if started[key] {
+				continue
+			}
+			started[key] = true
+			running++
+
+			go func(dep dependency.Dependency) {
+				results <- buildResult{dep: dep, err: container.buildSingleton(ctx, dep)}
+			}(lookup[key])
+		}
+
+		if running == 0 && len(ready) == 0 {
+			// the remaining singletons depend on each other. Build them anyway so the circular dependency is reported
+			for _, dep := range singletons {
+				if !started[keyOf(dep)] {
+					ready = append(ready, keyOf(dep))
+				}
+			}
+			continue
+		}
+
+		result := <-results
+		running--
+		finish(result)
+	}
+
+	// report the errors sorted by dependency name so they are stable across runs
+	var joined []error
+	for _, dep := range singletons {
+		if err, ok := errs[keyOf(dep)]; ok {
+			joined = append(joined, fmt.Errorf("failed to build dependency '%s': %w", dep.GetName(), err))
+		}
+	}
+
+	return errors.Join(joined...)
+}
+
+// buildSingleton builds the singleton unless the context is done. Every build is a resolution of its own so parallel builds waiting on each other are detected
+func (container *EctoContainer) buildSingleton(ctx context.Context, dep dependency.Dependency) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, _, err := container.getDependency(ctx, dep, []dependency.Dependency{}, &resolution{})
+	return err
+}
+
+// getSingletonDependencies gets the singletons the dependency needs to be built. Scoped and transient dependencies are walked through
+// because they are built as part of the dependency
+func (container *EctoContainer) getSingletonDependencies(dep dependency.Dependency) []registrationKey {
+	var singletons []registrationKey
+	seen := map[registrationKey]bool{keyOf(dep): true}
+
+	children, _ := container.validateDependency(dep)
+	queue := append([]dependency.Dependency{}, children...)
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+
+		key := keyOf(child)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if child.GetLifecycle() == lifecycles.Singleton {
+			singletons = append(singletons, key)
+			continue
+		}
+
+		children, _ := container.validateDependency(child)
+		queue = append(queue, children...)
+	}
+
+	return singletons
+}
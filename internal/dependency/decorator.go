@@ -0,0 +1,70 @@
+package dependency
+
+import (
+	"fmt"
+	"reflect"
+
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// EctoDecorator is the registration of a decorator. It is immutable once created so it can be shared by concurrent resolutions
+type EctoDecorator struct {
+	decoratedType reflect.Type
+	fn            reflect.Value
+	fnName        string
+	innerIndex    int
+}
+
+// GetDecoratedType gets the type of the instances the decorator wraps
+func (d *EctoDecorator) GetDecoratedType() reflect.Type {
+	return d.decoratedType
+}
+
+// GetFunc gets the decorator func
+func (d *EctoDecorator) GetFunc() reflect.Value {
+	return d.fn
+}
+
+// GetFuncName gets the name of the decorator func
+func (d *EctoDecorator) GetFuncName() string {
+	return d.fnName
+}
+
+// GetInnerIndex gets the index of the arg the instance is passed to
+func (d *EctoDecorator) GetInnerIndex() int {
+	return d.innerIndex
+}
+
+// NewDecorator creates a new EctoDecorator for a decorator func. The first arg of the decorated type gets the instance and every other arg is resolved from the container
+// decoratedType: The type of the instances the decorator wraps
+// decorator: The decorator func. Must return the decorated type and optionally an error
+func NewDecorator(decoratedType reflect.Type, decorator any) (*EctoDecorator, error) {
+	fn := reflect.ValueOf(decorator)
+	if decorator == nil || fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, fmt.Errorf("decorator must be a func but is %T", decorator)
+	}
+
+	fnName := ectoreflect.GetFuncName(fn)
+	fnType := fn.Type()
+	if fnType.IsVariadic() {
+		return nil, fmt.Errorf("decorator '%s' cannot be variadic", fnName)
+	}
+
+	typeName := ectoreflect.GetReflectTypeName(decoratedType)
+	if fnType.NumOut() == 0 || fnType.NumOut() > 2 || fnType.Out(0) != decoratedType || (fnType.NumOut() == 2 && fnType.Out(1) != errorType) {
+		return nil, fmt.Errorf("decorator '%s' must return '%s' and optionally an error", fnName, typeName)
+	}
+
+	for i := 0; i < fnType.NumIn(); i++ {
+		if fnType.In(i) == decoratedType {
+			return &EctoDecorator{
+				decoratedType: decoratedType,
+				fn:            fn,
+				fnName:        fnName,
+				innerIndex:    i,
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("decorator '%s' must have an arg of type '%s' for the instance it decorates", fnName, typeName)
+}
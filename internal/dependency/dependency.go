@@ -4,54 +4,74 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
+	ectodependency "github.com/Gobusters/ectoinject/dependency"
 	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
 	"github.com/Gobusters/ectoinject/lifecycles"
 )
 
+// EctoDependency is the registration of a dependency. It is immutable once created so it can be shared by concurrent resolutions
 type EctoDependency struct {
 	dependencyType      reflect.Type
 	dependencyName      string
+	named               bool
 	dependencyValueType reflect.Type
 	lifecycle           string
-	value               reflect.Value
 	getInstanceFunc     func(context.Context) (any, error)
 	constructor         reflect.Method
 	constructorName     string
-	instance            any
+	isInstance          bool
+	provider            reflect.Value
+	providerName        string
+	resultOf            ectodependency.Dependency
+	product             ectodependency.Dependency
+	resultField         []int
 }
 
-// SetValue sets the value of the dependency
-func (d *EctoDependency) SetValue(v reflect.Value) error {
-	d.value = v
-	d.instance = ectoreflect.GetPointerOfValue(v)
+// errorType is the type of the error result of a provider
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
 
-	return nil
+// cleanupType is the type of the cleanup func result of a provider
+var cleanupType = reflect.TypeOf(func() {})
+
+// isProviderResult checks if the func returns the provided type, optionally a cleanup func and optionally an error
+func isProviderResult(fnType reflect.Type) bool {
+	switch fnType.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return fnType.Out(1) == errorType || fnType.Out(1) == cleanupType
+	case 3:
+		return fnType.Out(1) == cleanupType && fnType.Out(2) == errorType
+	}
+
+	return false
 }
 
-// GetValue gets the value of the dependency
-func (d *EctoDependency) GetValue() reflect.Value {
-	return d.value
+// outType is the marker embedded by result structs
+var outType = reflect.TypeOf(ectodependency.Out{})
+
+// IsResult checks if the type is a struct, or a pointer to a struct, that embeds dependency.Out
+// t: The type to check
+func IsResult(t reflect.Type) bool {
+	return ectoreflect.Embeds(t, outType)
 }
 
-// GetInstance gets the instance of the dependency
-func (d *EctoDependency) GetInstance() (any, error) {
-	if d.instance == nil {
-		return nil, fmt.Errorf("dependency '%s' has no instance", d.dependencyName)
-	}
-	val, err := ectoreflect.CastType(d.dependencyType, d.instance)
-	if err != nil {
-		return nil, fmt.Errorf("failed to cast dependency '%s' to type '%s': %w", d.dependencyName, d.dependencyType.Name(), err)
+// GetResultType gets the result struct returned by the constructor of the value type. Returns false if the constructor does not return a result struct
+// valueType: The type of the dependency value
+// constructorName: The name of the constructor func
+func GetResultType(valueType reflect.Type, constructorName string) (reflect.Type, bool) {
+	if constructorName == "" {
+		return nil, false
 	}
 
-	d.instance = val.Interface()
-
-	return d.instance, nil
-}
+	constructor, ok := ectoreflect.GetMethodByName(valueType, constructorName)
+	if !ok || constructor.Type.NumOut() == 0 || !IsResult(constructor.Type.Out(0)) {
+		return nil, false
+	}
 
-// HasValue checks if the dependency has a value
-func (d *EctoDependency) HasValue() bool {
-	return d.value != (reflect.Value{})
+	return constructor.Type.Out(0), true
 }
 
 // HasConstructor checks if the dependency has a constructor func
@@ -59,6 +79,21 @@ func (d *EctoDependency) HasConstructor() bool {
 	return d.constructor != (reflect.Method{})
 }
 
+// HasProvider checks if the dependency has a provider func
+func (d *EctoDependency) HasProvider() bool {
+	return d.provider.IsValid()
+}
+
+// GetProvider gets the provider func of the dependency
+func (d *EctoDependency) GetProvider() reflect.Value {
+	return d.provider
+}
+
+// GetProviderName gets the name of the provider func of the dependency
+func (d *EctoDependency) GetProviderName() string {
+	return d.providerName
+}
+
 // GetConstructor gets the constructor func of the dependency
 func (d *EctoDependency) GetConstructor() reflect.Method {
 	return d.constructor
@@ -79,11 +114,31 @@ func (d *EctoDependency) GetName() string {
 	return d.dependencyName
 }
 
+// IsNamed checks if the dependency was registered with a name. Unnamed dependencies are named after their type
+func (d *EctoDependency) IsNamed() bool {
+	return d.named
+}
+
 // GetDependencyValueType gets the type of the dependency value
 func (d *EctoDependency) GetDependencyValueType() reflect.Type {
 	return d.dependencyValueType
 }
 
+// IsInstance checks if the dependency is an instance provided by the user
+func (d *EctoDependency) IsInstance() bool {
+	return d.isInstance
+}
+
+// GetProduct gets the dependency created by the factory func of the dependency
+func (d *EctoDependency) GetProduct() ectodependency.Dependency {
+	return d.product
+}
+
+// GetResultOf gets the dependency whose result struct provides this dependency and the index of its field
+func (d *EctoDependency) GetResultOf() (ectodependency.Dependency, []int) {
+	return d.resultOf, d.resultField
+}
+
 // GetLifecycle returns the lifecycle of the dependency
 func (d *EctoDependency) GetLifecycle() string {
 	return d.lifecycle
@@ -97,7 +152,7 @@ func (d *EctoDependency) GetLifecycle() string {
 // valueType: The type of the dependency value
 // getInstanceFunc: a function that returns the instance
 func NewDependency[TType any](name, lifecycle, constructorName string, valueType reflect.Type, getInstanceFunc func(context.Context) (any, error)) (*EctoDependency, error) {
-	dep := &EctoDependency{}
+	dep := &EctoDependency{named: name != ""}
 	if name == "" {
 		// if a name is not provided, use the name of the interface
 		name = ectoreflect.GetIntefaceName[TType]()
@@ -119,6 +174,11 @@ func NewDependency[TType any](name, lifecycle, constructorName string, valueType
 		if ok {
 			dep.constructor = constructor
 		}
+
+		// a constructor that returns a result struct provides the result struct instead of the value so it must be registered as the result struct
+		if resultType, isResult := GetResultType(valueType, constructorName); isResult && resultType != dep.dependencyType {
+			return dep, fmt.Errorf("dependency '%s' has a '%s' func that returns the result struct '%s'. Register the dependency as '%s'", ectoreflect.GetReflectTypeName(valueType), constructorName, ectoreflect.GetReflectTypeName(resultType), ectoreflect.GetReflectTypeName(resultType))
+		}
 	}
 
 	if getInstanceFunc != nil {
@@ -127,3 +187,183 @@ func NewDependency[TType any](name, lifecycle, constructorName string, valueType
 
 	return dep, nil
 }
+
+// NewProviderDependency creates a new EctoDependency for a provider func. The args of the provider are resolved from the container and its first result is the dependency
+// name: The name of the dependency
+// lifecycle: The lifecycle of the dependency
+// provider: The provider func. Must return the dependency and optionally an error
+func NewProviderDependency(name, lifecycle string, provider any) (*EctoDependency, error) {
+	fn := reflect.ValueOf(provider)
+	if provider == nil || fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, fmt.Errorf("provider must be a func but is %T", provider)
+	}
+
+	providerName := ectoreflect.GetFuncName(fn)
+	fnType := fn.Type()
+	if fnType.IsVariadic() {
+		return nil, fmt.Errorf("provider '%s' cannot be variadic", providerName)
+	}
+
+	if !isProviderResult(fnType) {
+		return nil, fmt.Errorf("provider '%s' must return the provided type, optionally a cleanup func and optionally an error", providerName)
+	}
+
+	if !lifecycles.IsValid(lifecycle) {
+		return nil, fmt.Errorf("invalid lifecycle '%s' must be one of %v", lifecycle, lifecycles.Lifecycles)
+	}
+
+	dep := &EctoDependency{
+		dependencyType:      fnType.Out(0),
+		dependencyName:      name,
+		named:               name != "",
+		dependencyValueType: fnType.Out(0),
+		lifecycle:           lifecycle,
+		provider:            fn,
+		providerName:        providerName,
+	}
+	if name == "" {
+		// if a name is not provided, use the name of the provided type
+		dep.dependencyName = ectoreflect.GetReflectTypeName(fnType.Out(0))
+	}
+
+	return dep, nil
+}
+
+// NewFactoryDependency creates a new EctoDependency for a factory func type. The container creates the func, which builds a new TValue every time it is called.
+// The args of the func are passed to the constructor args or assisted fields of TValue and its other dependencies are resolved from the container
+// TFactory: The func type of the factory. Must return the created type and an error
+// name: The name of the dependency
+// lifecycle: The lifecycle of the factory func
+// constructorName: The name of the constructor func of TValue
+// valueType: The type of the struct created by the factory
+func NewFactoryDependency[TFactory any](name, lifecycle, constructorName string, valueType reflect.Type) (*EctoDependency, error) {
+	factoryType := reflect.TypeOf((*TFactory)(nil)).Elem()
+	factoryName := factoryType.String()
+	if factoryType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("factory '%s' must be a func type", factoryName)
+	}
+
+	if factoryType.IsVariadic() {
+		return nil, fmt.Errorf("factory '%s' cannot be variadic", factoryName)
+	}
+
+	if factoryType.NumOut() != 2 || factoryType.Out(1) != errorType {
+		return nil, fmt.Errorf("factory '%s' must return the created type and an error", factoryName)
+	}
+
+	productType := factoryType.Out(0)
+	if valueType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("factory '%s' must create a struct but '%s' is not a struct", factoryName, ectoreflect.GetReflectTypeName(valueType))
+	}
+
+	if !ectoreflect.CanCastType(productType, reflect.PointerTo(valueType)) {
+		return nil, fmt.Errorf("type '%s' is not assignable to '%s'", ectoreflect.GetReflectTypeName(valueType), ectoreflect.GetReflectTypeName(productType))
+	}
+
+	dep, err := NewDependency[TFactory](name, lifecycle, "", factoryType, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// the product is built on every call so it is transient
+	product := &EctoDependency{
+		dependencyType:      productType,
+		dependencyName:      ectoreflect.GetReflectTypeName(productType),
+		dependencyValueType: valueType,
+		lifecycle:           lifecycles.Transient,
+		constructorName:     constructorName,
+	}
+
+	if constructorName != "" {
+		if constructor, ok := ectoreflect.GetMethodByName(valueType, constructorName); ok {
+			product.constructor = constructor
+		}
+	}
+
+	dep.product = product
+
+	return dep, nil
+}
+
+// NewInstanceDependency creates a new singleton EctoDependency for an instance provided by the user. The instance is owned by the user so the container never disposes it
+// TType: The type of the dependency
+// name: The name of the dependency
+// instance: The instance of the dependency
+func NewInstanceDependency[TType any](name string, instance any) (*EctoDependency, error) {
+	getInstanceFunc := func(context.Context) (any, error) {
+		return instance, nil
+	}
+
+	dep, err := NewDependency[TType](name, lifecycles.Singleton, "", reflect.TypeOf((*TType)(nil)).Elem(), getInstanceFunc)
+	if err != nil {
+		return dep, err
+	}
+
+	dep.isInstance = true
+
+	return dep, nil
+}
+
+// NewTargetDependency creates a new transient EctoDependency for a struct created outside the container. It is never registered, it only describes the struct
+// while its fields are injected
+// valueType: The type of the struct
+func NewTargetDependency(valueType reflect.Type) *EctoDependency {
+	return &EctoDependency{
+		dependencyType:      reflect.PointerTo(valueType),
+		dependencyName:      ectoreflect.GetReflectTypeName(valueType),
+		dependencyValueType: valueType,
+		lifecycle:           lifecycles.Transient,
+	}
+}
+
+// NewInvokeDependency creates a new transient EctoDependency for a func called with args resolved from the container. It is never registered, it only describes
+// the func while its args are resolved
+// fn: The func
+func NewInvokeDependency(fn reflect.Value) *EctoDependency {
+	return &EctoDependency{
+		dependencyType:      fn.Type(),
+		dependencyName:      ectoreflect.GetFuncName(fn),
+		dependencyValueType: fn.Type(),
+		lifecycle:           lifecycles.Transient,
+	}
+}
+
+// NewResultDependencies creates a dependency for each exported field of the result struct provided by the parent. The field dependencies share the lifecycle of the parent
+// parent: The dependency that provides the result struct
+// tagName: The name of the tag used to name the fields. Fields tagged with "-" are skipped
+func NewResultDependencies(parent ectodependency.Dependency, tagName string) []*EctoDependency {
+	resultType := parent.GetDependencyType()
+	if resultType.Kind() == reflect.Ptr {
+		resultType = resultType.Elem()
+	}
+
+	var deps []*EctoDependency
+	for i := 0; i < resultType.NumField(); i++ {
+		field := resultType.Field(i)
+		if (field.Anonymous && field.Type == outType) || !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get(tagName), ",")
+		if name == "-" {
+			continue
+		}
+
+		dep := &EctoDependency{
+			dependencyType:      field.Type,
+			dependencyName:      name,
+			named:               name != "",
+			dependencyValueType: field.Type,
+			lifecycle:           parent.GetLifecycle(),
+			resultOf:            parent,
+			resultField:         field.Index,
+		}
+		if name == "" {
+			dep.dependencyName = ectoreflect.GetReflectTypeName(field.Type)
+		}
+
+		deps = append(deps, dep)
+	}
+
+	return deps
+}
@@ -3,6 +3,7 @@ package reflect
 import (
 	"fmt"
 	"reflect"
+	"runtime"
 	"unsafe"
 )
 
@@ -14,7 +15,7 @@ func GetIntefaceName[T any]() string {
 	return GetReflectTypeName(interfaceType)
 }
 
-// GetReflectTypeName returns the name of the type in the format `modulePath.typeName`
+// GetReflectTypeName returns the name of the type in the format `modulePath.typeName`. Unnamed types such as slices, maps and funcs use the format of reflect.Type.String
 // t: The type to get the name of
 func GetReflectTypeName(t reflect.Type) string {
 	// if t is a pointer, dereference it
@@ -24,6 +25,10 @@ func GetReflectTypeName(t reflect.Type) string {
 
 	pkgPath := t.PkgPath()
 	name := t.Name()
+	if name == "" {
+		// unnamed types do not have a package path
+		return t.String()
+	}
 
 	return pkgPath + "." + name
 }
@@ -106,6 +111,40 @@ func CastType(t reflect.Type, v any) (reflect.Value, error) {
 	return vValue, nil
 }
 
+// CanCastType checks if a value of type v can be casted to type t with CastType. If v is an interface the type of the value it holds is unknown, so it is assumed it can be casted
+// t: The type to cast to
+// v: The type of the value to cast
+func CanCastType(t reflect.Type, v reflect.Type) bool {
+	if v.Kind() == reflect.Interface {
+		return true
+	}
+
+	// if v is already the correct type or implements the interface
+	if v == t || (t.Kind() == reflect.Interface && v.Implements(t)) {
+		return true
+	}
+
+	isTInterface := t.Kind() == reflect.Interface
+	isTPtr := isTInterface || t.Kind() == reflect.Ptr
+	isVPtr := v.Kind() == reflect.Ptr
+
+	// CastType uses the address of v if T is a pointer or interface and v is not
+	if isTPtr && !isVPtr {
+		v = reflect.PointerTo(v)
+	}
+
+	// CastType dereferences v if T is not a pointer or interface and v is
+	if !isTPtr && isVPtr {
+		v = v.Elem()
+	}
+
+	if isTInterface {
+		return v.Implements(t)
+	}
+
+	return v == t
+}
+
 // Cast casts a value to the provided type. Handles resolving pointers and interfaces. Returns the casted value and an error if the value cannot be casted
 // T: The type to cast to
 // v: The value to cast
@@ -126,15 +165,6 @@ func Cast[T any](v any) (T, error) {
 // field: The field to set
 // value: The value to set the field to
 func SetField(target reflect.Value, field reflect.StructField, value reflect.Value) error {
-	// get field index
-	index := field.Index
-
-	// get the value of the field
-	fieldVal := target.FieldByIndex(index)
-
-	// can the field be set?
-	canSet := fieldVal.CanSet()
-
 	var val any // the value to set
 	if value.Kind() == reflect.Ptr || !value.CanAddr() {
 		val = value.Interface()
@@ -147,18 +177,24 @@ func SetField(target reflect.Value, field reflect.StructField, value reflect.Val
 		return err
 	}
 
-	if canSet {
-		// Set the value directly if it's settable
-		fieldVal.Set(value)
-	} else {
-		// If not settable, use unsafe to set the value
-		ptr := reflect.NewAt(field.Type, unsafe.Pointer(fieldVal.UnsafeAddr())).Elem()
-		ptr.Set(value)
-	}
+	GetSettableField(target, field).Set(value)
 
 	return nil
 }
 
+// GetSettableField gets the value of a field of the struct that can be set. Fields that cannot be set directly, such as unexported fields, are accessed with unsafe
+// target: The addressable struct
+// field: The field to get
+func GetSettableField(target reflect.Value, field reflect.StructField) reflect.Value {
+	fieldVal := target.FieldByIndex(field.Index)
+	if fieldVal.CanSet() {
+		return fieldVal
+	}
+
+	// If not settable, use unsafe to access the value
+	return reflect.NewAt(field.Type, unsafe.Pointer(fieldVal.UnsafeAddr())).Elem()
+}
+
 // GetPointerOfValue gets the pointer of a value. If the value is already a pointer, it will return the value. If the value is not addressable, it will return the value. Otherwise, it will return the address of the value
 // val: The value to get the pointer of
 func GetPointerOfValue(val reflect.Value) any {
@@ -207,3 +243,34 @@ func typeImplementsInterface(t reflect.Type, interfaceType reflect.Type) bool {
 
 	return false
 }
+
+// Embeds checks if the type is a struct, or a pointer to a struct, that embeds the marker type
+// t: The type to check
+// marker: The embedded type to look for
+func Embeds(t reflect.Type, marker reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Anonymous && field.Type == marker {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetFuncName gets the name of a func including its package, such as `main.NewServer`
+// fn: The func to get the name of
+func GetFuncName(fn reflect.Value) string {
+	if f := runtime.FuncForPC(fn.Pointer()); f != nil {
+		return f.Name()
+	}
+
+	return fn.Type().String()
+}
@@ -66,3 +66,21 @@ func TestCastType(t *testing.T) {
 
 	assert.Equal(t, "test", castInstance.Interface().(TestDep).GetString())
 }
+
+func TestCanCastType(t *testing.T) {
+	depType := reflect.TypeOf((*TestDep)(nil)).Elem()
+	dep1Type := reflect.TypeOf(TestDep1{})
+	stringType := reflect.TypeOf("")
+
+	assert.True(t, CanCastType(depType, reflect.PointerTo(dep1Type)))
+	assert.True(t, CanCastType(depType, dep1Type))
+	assert.True(t, CanCastType(dep1Type, reflect.PointerTo(dep1Type)))
+	assert.True(t, CanCastType(reflect.PointerTo(dep1Type), dep1Type))
+	assert.True(t, CanCastType(stringType, depType), "interfaces may hold any value")
+	assert.False(t, CanCastType(stringType, dep1Type))
+	assert.False(t, CanCastType(depType, stringType))
+}
+
+func TestGetFuncName(t *testing.T) {
+	assert.Equal(t, "github.com/Gobusters/ectoinject/internal/reflect.CanCastType", GetFuncName(reflect.ValueOf(CanCastType)))
+}
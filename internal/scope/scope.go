@@ -2,42 +2,254 @@ package scope
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
 
 	"github.com/Gobusters/ectoinject/dependency"
 )
 
 type contextKey string
 
-var contextScopedContainerIDKey = contextKey("ectoinject-dependency-scoped-container")
+var contextScopeKey = contextKey("ectoinject-dependency-scope")
+var contextRootScopeKey = contextKey("ectoinject-dependency-root-scope")
 
-// AddScopedDependency adds a scoped dependency to the context. This allows for scoped caching of dependencies on the context
-// ctx: The context to add the scoped dependency to
-// dep: The dependency to add
-func AddScopedDependency(ctx context.Context, dep dependency.Dependency) context.Context {
-	// get the scoped cache from the context
-	cache, ok := ctx.Value(contextScopedContainerIDKey).(map[string]dependency.Dependency)
-	if !ok {
-		cache = make(map[string]dependency.Dependency)
+// Scope caches the instances created during its lifetime. When the scope is closed, the instances are disposed in reverse creation order. A scope is safe for concurrent use
+type Scope struct {
+	mu        sync.Mutex
+	instances map[any]*Instance // instances by key
+	created   []*Instance       // successfully built instances in the order they were built
+	closed    bool
+}
+
+// Instance is an instance of a dependency cached by a scope. It is built by the resolution that claimed it. Every other resolution waits for it to be built
+type Instance struct {
+	Owner     any                         // the resolution building the instance
+	dep       dependency.Dependency       // the dependency the instance was built for
+	done      chan struct{}               // closed once the instance has been built or the build has failed
+	value     reflect.Value               // the instance of the dependency
+	err       error                       // the error returned while building the instance
+	dispose   func(context.Context) error // disposes the instance when the scope is closed
+	tracked   bool                        // set for instances that are only tracked to be disposed, such as transient instances
+	forgotten bool                        // set for instances that were replaced by a new instance. Guarded by the scope lock
+}
+
+// New creates a new scope
+func New() *Scope {
+	return &Scope{
+		instances: make(map[any]*Instance),
 	}
+}
+
+// WithScope adds the scope to the context. Scoped dependencies resolved with the context are cached by the scope
+// ctx: The context to add the scope to
+// s: The scope to add
+func WithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, contextScopeKey, s)
+}
+
+// FromContext gets the scope from the context. Returns the scope and a bool indicating if the scope was found
+// ctx: The context to get the scope from
+func FromContext(ctx context.Context) (*Scope, bool) {
+	s, ok := ctx.Value(contextScopeKey).(*Scope)
+	return s, ok
+}
 
-	// add the dependency to the cache
-	cache[dep.GetName()] = dep
+// WithRootScope marks the context to use the root scope of the container when it does not have a scope
+// ctx: The context to mark
+func WithRootScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextRootScopeKey, true)
+}
 
-	// add the cache to the context
-	return context.WithValue(ctx, contextScopedContainerIDKey, cache)
+// UsesRootScope checks if the context was marked with WithRootScope
+// ctx: The context to check
+func UsesRootScope(ctx context.Context) bool {
+	root, _ := ctx.Value(contextRootScopeKey).(bool)
+	return root
 }
 
-// GetScopedDependency gets a scoped dependency from the context. Returns the dependency and a bool indicating if the dependency was found
-// ctx: The context to get the scoped dependency from
-// dependencyName: The name of the dependency to get
-func GetScopedDependency(ctx context.Context, dependencyName string) (dependency.Dependency, bool) {
-	// get the scoped cache from the context
-	cache, ok := ctx.Value(contextScopedContainerIDKey).(map[string]dependency.Dependency)
-	if !ok {
-		cache = make(map[string]dependency.Dependency)
+// Claim gets the instance with the provided key. If the scope does not have the instance, a new instance is claimed for owner and true is returned. The owner must call Complete once it has built the instance
+// key: The key of the instance. Must be comparable
+// dep: The dependency the instance is built for
+// owner: The resolution claiming the instance
+func (s *Scope) Claim(key any, dep dependency.Dependency, owner any) (*Instance, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, false, fmt.Errorf("scope is closed")
+	}
+
+	instance, ok := s.instances[key]
+	if ok {
+		return instance, false, nil
 	}
 
-	// get the dependency from the cache
-	dep, ok := cache[dependencyName]
-	return dep, ok
+	instance = &Instance{Owner: owner, dep: dep, done: make(chan struct{})}
+	s.instances[key] = instance
+
+	return instance, true, nil
+}
+
+// Complete records the result of building a claimed instance and wakes every resolution waiting on it. A failed instance is forgotten so it can be built again.
+// If the scope was closed while the instance was being built, the instance is disposed immediately and an error is returned
+// key: The key of the instance. Must be comparable
+// instance: The claimed instance
+// value: The built value
+// err: The error returned while building the value
+// dispose: (optional) disposes the value when the scope is closed
+func (s *Scope) Complete(key any, instance *Instance, value reflect.Value, err error, dispose func(context.Context) error) error {
+	instance.value, instance.dispose = value, dispose
+
+	s.mu.Lock()
+	closed := s.closed
+	if err != nil || closed {
+		if s.instances[key] == instance {
+			delete(s.instances, key)
+		}
+	} else {
+		s.created = append(s.created, instance)
+	}
+	s.mu.Unlock()
+
+	if err == nil && closed {
+		err = fmt.Errorf("scope was closed while '%s' was being built", instance.dep.GetName())
+		if dispose != nil {
+			err = errors.Join(err, dispose(context.Background()))
+		}
+	}
+
+	instance.err = err
+	close(instance.done)
+
+	return err
+}
+
+// Track adds the dispose func of an instance that is not cached by the scope, such as a transient instance, so it is disposed when the scope is closed.
+// If the scope has already been closed, the instance is disposed immediately and an error is returned
+// dep: The dependency the instance was built for
+// dispose: disposes the instance when the scope is closed
+func (s *Scope) Track(dep dependency.Dependency, dispose func(context.Context) error) error {
+	instance := &Instance{dep: dep, done: make(chan struct{}), dispose: dispose, tracked: true}
+	close(instance.done)
+
+	s.mu.Lock()
+	closed := s.closed
+	if !closed {
+		s.created = append(s.created, instance)
+	}
+	s.mu.Unlock()
+
+	if closed {
+		return errors.Join(fmt.Errorf("scope was closed while '%s' was being built", dep.GetName()), dispose(context.Background()))
+	}
+
+	return nil
+}
+
+// Forget removes the instance with the provided key so it will be built again. A forgotten instance is still disposed when the scope is closed,
+// but it is no longer returned by Instances
+// key: The key of the instance. Must be comparable
+func (s *Scope) Forget(key any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if instance, ok := s.instances[key]; ok {
+		instance.forgotten = true
+		delete(s.instances, key)
+	}
+}
+
+// Instances gets the successfully built instances in the order they were built. Tracked and forgotten instances are not included
+func (s *Scope) Instances() []*Instance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var instances []*Instance
+	for _, instance := range s.created {
+		if !instance.tracked && !instance.forgotten {
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances
+}
+
+// Len gets the number of instances cached by the scope
+func (s *Scope) Len() int {
+	return len(s.Instances())
+}
+
+// IsClosed checks if the scope has been closed
+func (s *Scope) IsClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// Close ends the scope and disposes its instances in reverse creation order. Returns the errors of every failed dispose.
+// Stops disposing instances if the context is done
+// ctx: The context passed to the dispose funcs
+func (s *Scope) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	created := s.created
+	s.created = nil
+	s.instances = make(map[any]*Instance)
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("%d instances were not disposed: %w", i+1, err))
+			break
+		}
+
+		dispose := created[i].dispose
+		if dispose == nil {
+			continue
+		}
+
+		if err := disposeWithContext(ctx, dispose); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// disposeWithContext calls the dispose func. Stops waiting for the dispose func to return if the context is done
+func disposeWithContext(ctx context.Context, dispose func(context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- dispose(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("stopped waiting for instance to be disposed: %w", ctx.Err())
+	}
+}
+
+// Done gets a channel that is closed once the instance has been built or the build has failed
+func (i *Instance) Done() <-chan struct{} {
+	return i.done
+}
+
+// Dependency gets the dependency the instance was built for
+func (i *Instance) Dependency() dependency.Dependency {
+	return i.dep
+}
+
+// Value gets the built value of the instance. Must only be called once Done is closed
+func (i *Instance) Value() (reflect.Value, error) {
+	return i.value, i.err
 }
@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/Gobusters/ectoinject/ectocontainer"
 )
@@ -12,11 +13,17 @@ var defaultContainerSet = false
 // singleton instance of the ectocontainer.DIContainers
 var containers = map[string]ectocontainer.DIContainer{}
 
+// guards containers, defaultContainerID and defaultContainerSet
+var mu sync.RWMutex
+
 func RegisterContainer(container ectocontainer.DIContainer) error {
 	if container == nil {
 		return fmt.Errorf("container cannot be nil")
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+
 	if _, ok := containers[container.GetContainerID()]; ok {
 		return fmt.Errorf("container with id '%s' already exists", container.GetContainerID())
 	}
@@ -36,6 +43,9 @@ func GetContainer(id string) ectocontainer.DIContainer {
 		return nil
 	}
 
+	mu.RLock()
+	defer mu.RUnlock()
+
 	container, ok := containers[id]
 	if !ok {
 		return nil
@@ -45,7 +55,7 @@ func GetContainer(id string) ectocontainer.DIContainer {
 }
 
 func GetDefaultContainer() ectocontainer.DIContainer {
-	return GetContainer(defaultContainerID)
+	return GetContainer(GetDefaultContainerID())
 }
 
 func SetDefaultContainer(containerID string) error {
@@ -53,6 +63,9 @@ func SetDefaultContainer(containerID string) error {
 		return fmt.Errorf("containerID cannot be empty")
 	}
 
+	mu.Lock()
+	defer mu.Unlock()
+
 	if _, ok := containers[containerID]; !ok {
 		return fmt.Errorf("container with id '%s' does not exist", containerID)
 	}
@@ -63,5 +76,8 @@ func SetDefaultContainer(containerID string) error {
 }
 
 func GetDefaultContainerID() string {
+	mu.RLock()
+	defer mu.RUnlock()
+
 	return defaultContainerID
 }
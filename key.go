@@ -0,0 +1,79 @@
+package ectoinject
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// Key is a typed token for a dependency. A key carries the type of its dependency, so getting the dependency of a key as the wrong type does not compile
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a new key for a dependency
+// T: The type of the dependency
+// name: (optional) The name of the dependency. Keys without a name are for the unnamed dependency of type T
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name returns the name of the dependency. Empty for unnamed dependencies
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Type returns the type of the dependency
+func (k Key[T]) Type() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// String returns the name of the dependency. Unnamed dependencies are named after their type
+func (k Key[T]) String() string {
+	if k.name == "" {
+		return ectoreflect.GetIntefaceName[T]()
+	}
+
+	return k.name
+}
+
+// RegisterWithKey registers a dependency in the container with the type and name of the key
+// TValue: The implementation of the dependency
+// TType: The type of the dependency. Inferred from the key
+// container: The container to register the dependency in
+// lifecycle: The lifecycle of the dependency
+// key: The key of the dependency
+func RegisterWithKey[TValue any, TType any](container ectocontainer.DIContainer, lifecycle string, key Key[TType]) error {
+	return RegisterDependency[TType, TValue](container, lifecycle, key.name)
+}
+
+// RegisterInstanceWithKey registers an instance in the container with the type and name of the key. Instances are treated as singletons
+// TType: The type of the dependency. Inferred from the key
+// container: The container to register the dependency in
+// key: The key of the dependency
+// instance: The instance to register
+func RegisterInstanceWithKey[TType any](container ectocontainer.DIContainer, key Key[TType], instance TType) error {
+	return RegisterInstance[TType](container, instance, key.name)
+}
+
+// RegisterInstanceFuncWithKey registers a custom instance function in the container with the type and name of the key
+// TType: The type of the dependency. Inferred from the key
+// container: The container to register the dependency in
+// lifecycle: The lifecycle of the dependency. Must be one of transient, scoped, or singleton
+// key: The key of the dependency
+// getInstanceFunc: a function that returns the instance
+func RegisterInstanceFuncWithKey[TType any](container ectocontainer.DIContainer, lifecycle string, key Key[TType], getInstanceFunc func(context.Context) (TType, error)) error {
+	return RegisterInstanceFunc[TType](container, lifecycle, func(ctx context.Context) (any, error) {
+		return getInstanceFunc(ctx)
+	}, key.name)
+}
+
+// GetWithKey gets the dependency of the key from the container. Returns a context with scoped dependencies caching, the dependency, and an error
+// T: The type of the dependency. Inferred from the key
+// ctx: The context to use. To use a non-default container, use SetActiveContainer
+// key: The key of the dependency
+func GetWithKey[T any](ctx context.Context, key Key[T]) (context.Context, T, error) {
+	return GetNamedDependency[T](ctx, key.name)
+}
@@ -0,0 +1,77 @@
+package ectoinject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	fidoKey     = NewKey[Animal]("fido")
+	whiskersKey = NewKey[Animal]("whiskers")
+	ownerKey    = NewKey[Person]("owner")
+	settingsKey = NewKey[*settings]("")
+)
+
+func TestKeys(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test keys",
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterWithKey[Dog](container, lifecycles.Singleton, fidoKey))
+	assert.Nil(t, RegisterInstanceWithKey(container, ownerKey, Person(&Human{Name: "jane"})))
+	assert.Nil(t, RegisterInstanceFuncWithKey(container, lifecycles.Transient, whiskersKey, func(ctx context.Context) (Animal, error) {
+		return &Cat{}, nil
+	}))
+	assert.Nil(t, RegisterInstanceWithKey(container, settingsKey, &settings{Source: "key"}))
+
+	assert.Nil(t, container.Validate(fidoKey, whiskersKey, ownerKey, settingsKey))
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, fido, err := GetWithKey(ctx, fidoKey)
+	assert.Nil(t, err, "error getting fido")
+	assert.IsType(t, &Dog{}, fido)
+
+	_, whiskers, err := GetWithKey(ctx, whiskersKey)
+	assert.Nil(t, err, "error getting whiskers")
+	assert.IsType(t, &Cat{}, whiskers)
+
+	_, owner, err := GetWithKey(ctx, ownerKey)
+	assert.Nil(t, err, "error getting owner")
+	assert.Equal(t, "jane", owner.(*Human).Name)
+
+	_, s, err := GetWithKey(ctx, settingsKey)
+	assert.Nil(t, err, "error getting settings")
+	assert.Equal(t, "key", s.Source)
+
+	// keys are names, so they can be used in inject tags
+	assert.Equal(t, "fido", fidoKey.String())
+	assert.Equal(t, "github.com/Gobusters/ectoinject.settings", settingsKey.String())
+}
+
+func TestValidateKeys(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test validate keys",
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	// fido is registered as a human, but the key is for a dog
+	assert.Nil(t, RegisterSingleton[Human, Human](container, "fido"))
+
+	err = container.Validate(NewKey[*Dog]("fido"), ownerKey)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "dependency for owner not found")
+	assert.ErrorContains(t, err, "key 'fido' has type '*ectoinject.Dog' which cannot be assigned dependency 'fido' of type 'ectoinject.Human'")
+}
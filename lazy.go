@@ -0,0 +1,94 @@
+package ectoinject
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	ectoreflect "github.com/Gobusters/ectoinject/internal/reflect"
+)
+
+// Lazy resolves a dependency the first time Get is called and caches it. Inject a Lazy[T] to delay building a heavy dependency until it is used,
+// or to let two dependencies reference each other. The dependency is resolved with the container and scope the Lazy was injected from.
+// Copies of a Lazy share the cached dependency. A Lazy is safe for concurrent use
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+// lazyState is the state shared by the copies of a Lazy
+type lazyState[T any] struct {
+	mu       sync.Mutex
+	resolve  func() (any, error) // resolves the dependency
+	resolved bool                // set once the dependency has been resolved
+	val      T                   // the resolved dependency
+}
+
+// Get resolves the dependency on the first call and returns the cached dependency on every other call. A failed resolution is tried again on the next call
+func (l Lazy[T]) Get() (T, error) {
+	var zero T
+	if l.state == nil {
+		return zero, fmt.Errorf("lazy dependency '%s' was not injected by a container", ectoreflect.GetIntefaceName[T]())
+	}
+
+	l.state.mu.Lock()
+	defer l.state.mu.Unlock()
+
+	if l.state.resolved {
+		return l.state.val, nil
+	}
+
+	val, err := resolveDeferred[T](l.state.resolve)
+	if err != nil {
+		return zero, err
+	}
+
+	l.state.val, l.state.resolved = val, true
+	return val, nil
+}
+
+// DependencyType gets the type of the wrapped dependency
+func (l *Lazy[T]) DependencyType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// SetResolver sets the func used to resolve the wrapped dependency. Called by the container when the Lazy is injected
+func (l *Lazy[T]) SetResolver(resolve func() (any, error)) {
+	l.state = &lazyState[T]{resolve: resolve}
+}
+
+// Provider resolves a dependency every time Get is called. The lifecycle of the dependency is respected, so a transient dependency is built on every call
+// while a singleton is built once. The dependency is resolved with the container and scope the Provider was injected from
+type Provider[T any] struct {
+	resolve func() (any, error)
+}
+
+// Get resolves the dependency
+func (p Provider[T]) Get() (T, error) {
+	if p.resolve == nil {
+		var zero T
+		return zero, fmt.Errorf("provider of dependency '%s' was not injected by a container", ectoreflect.GetIntefaceName[T]())
+	}
+
+	return resolveDeferred[T](p.resolve)
+}
+
+// DependencyType gets the type of the wrapped dependency
+func (p *Provider[T]) DependencyType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// SetResolver sets the func used to resolve the wrapped dependency. Called by the container when the Provider is injected
+func (p *Provider[T]) SetResolver(resolve func() (any, error)) {
+	p.resolve = resolve
+}
+
+// resolveDeferred resolves the dependency of a Lazy or a Provider and casts it to T
+func resolveDeferred[T any](resolve func() (any, error)) (T, error) {
+	instance, err := resolve()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return ectoreflect.Cast[T](instance)
+}
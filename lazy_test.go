@@ -0,0 +1,171 @@
+package ectoinject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type chicken struct {
+	Egg Lazy[*egg] `inject:""`
+}
+
+type egg struct {
+	Chicken *chicken `inject:""`
+}
+
+type ticket struct {
+	id int
+}
+
+type ticketBooth struct {
+	tickets Provider[*ticket]
+	chicken Lazy[*chicken]
+}
+
+func (b *ticketBooth) Constructor(tickets Provider[*ticket], chicken Lazy[*chicken]) *ticketBooth {
+	return &ticketBooth{tickets: tickets, chicken: chicken}
+}
+
+func TestLazy(t *testing.T) {
+	built := 0
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test lazy"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterSingleton[chicken, chicken](container))
+	assert.Nil(t, RegisterSingleton[egg, egg](container))
+	assert.Nil(t, RegisterProvider(container, lifecycles.Scoped, func() *ticket {
+		built++
+		return &ticket{id: built}
+	}))
+	assert.Nil(t, RegisterScoped[ticketBooth, ticketBooth](container))
+
+	// the lazy reference breaks the cycle between the chicken and the egg
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test lazy")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, c, err := GetContext[*chicken](ctx)
+	assert.Nil(t, err, "error getting chicken")
+
+	e, err := c.Egg.Get()
+	assert.Nil(t, err, "error getting egg")
+	fromEgg, err := e.Chicken.Egg.Get()
+	assert.Nil(t, err, "error getting egg")
+	assert.Same(t, e, fromEgg, "egg should reference the chicken singleton")
+
+	again, err := c.Egg.Get()
+	assert.Nil(t, err, "error getting egg")
+	assert.Same(t, e, again, "lazy should cache the dependency")
+
+	_, booth, err := GetContext[*ticketBooth](ctx)
+	assert.Nil(t, err, "error getting ticket booth")
+	assert.Equal(t, 0, built, "lazy and provider dependencies should not be built when they are injected")
+
+	boothChicken, err := booth.chicken.Get()
+	assert.Nil(t, err, "error getting chicken")
+	fromBooth, err := boothChicken.Egg.Get()
+	assert.Nil(t, err, "error getting egg")
+	assert.Same(t, e, fromBooth, "booth should reference the chicken singleton")
+
+	var unset Lazy[*chicken]
+	_, err = unset.Get()
+	assert.ErrorContains(t, err, "was not injected by a container")
+}
+
+func TestProvider(t *testing.T) {
+	built := 0
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test provider"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterSingleton[chicken, chicken](container))
+	assert.Nil(t, RegisterSingleton[egg, egg](container))
+	assert.Nil(t, RegisterProvider(container, lifecycles.Scoped, func() *ticket {
+		built++
+		return &ticket{id: built}
+	}))
+	assert.Nil(t, RegisterScoped[ticketBooth, ticketBooth](container))
+
+	ctx, err := SetActiveContainer(context.Background(), "test provider")
+	assert.Nil(t, err, "error setting active container")
+
+	// the provider captures the scope the scoped booth was injected from
+	ctx, s := NewScope(ctx)
+	_, booth, err := GetContext[*ticketBooth](ctx)
+	assert.Nil(t, err, "error getting ticket booth")
+
+	first, err := booth.tickets.Get()
+	assert.Nil(t, err, "error getting ticket")
+	second, err := booth.tickets.Get()
+	assert.Nil(t, err, "error getting ticket")
+	assert.Same(t, first, second, "scoped tickets should be cached by the captured scope")
+	assert.Equal(t, 1, built)
+
+	assert.Nil(t, s.Close(context.Background()))
+	_, err = booth.tickets.Get()
+	assert.ErrorContains(t, err, "scope is closed")
+}
+
+type henHouse struct {
+	Chicken Lazy[*chicken]    `inject:""`
+	Tickets Provider[*ticket] `inject:""`
+}
+
+func TestLazyInSingletonOutlivesRequest(t *testing.T) {
+	built := 0
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test lazy in singleton"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterSingleton[chicken, chicken](container))
+	assert.Nil(t, RegisterSingleton[egg, egg](container))
+	assert.Nil(t, RegisterProvider(container, lifecycles.Scoped, func() *ticket {
+		built++
+		return &ticket{id: built}
+	}))
+	assert.Nil(t, RegisterScoped[ticketBooth, ticketBooth](container))
+
+	assert.Nil(t, RegisterSingleton[henHouse, henHouse](container))
+
+	ctx, err := SetActiveContainer(context.Background(), "test lazy in singleton")
+	assert.Nil(t, err, "error setting active container")
+
+	// build the singleton while handling a request
+	ctx, cancel := context.WithCancel(ctx)
+	ctx, s := NewScope(ctx)
+	_, house, err := GetContext[*henHouse](ctx)
+	assert.Nil(t, err, "error getting hen house")
+
+	// the request ends before the singleton uses its lazy dependencies
+	cancel()
+	assert.Nil(t, s.Close(context.Background()))
+
+	c, err := house.Chicken.Get()
+	assert.Nil(t, err, "error getting chicken")
+	assert.NotNil(t, c)
+
+	first, err := house.Tickets.Get()
+	assert.Nil(t, err, "error getting ticket")
+	second, err := house.Tickets.Get()
+	assert.Nil(t, err, "error getting ticket")
+	assert.Same(t, first, second, "scoped tickets should be cached by the root scope")
+}
+
+type lostChicken struct {
+	Egg Lazy[*Cat] `inject:""`
+}
+
+func TestLazyMissingDependency(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test lazy missing dependency"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterTransient[lostChicken, lostChicken](container))
+	assert.ErrorIs(t, container.Validate(), ErrNotFound)
+
+	_, err = GetFromContainer[*lostChicken]("test lazy missing dependency")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "github.com/Gobusters/ectoinject.Cat")
+}
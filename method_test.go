@@ -0,0 +1,102 @@
+package ectoinject
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/stretchr/testify/assert"
+)
+
+// money cannot expose injectable fields without breaking its invariants
+type money struct {
+	owner    Person
+	pet      Animal
+	currency string
+	ready    bool
+}
+
+type moneyPet struct {
+	In
+	Pet Animal `inject:"rex"`
+}
+
+func (m *money) InjectOwner(owner Person) {
+	m.owner = owner
+}
+
+func (m *money) InjectPet(ctx context.Context, params moneyPet) error {
+	if params.Pet == nil {
+		return errors.New("pet is required")
+	}
+	m.pet = params.Pet
+	return nil
+}
+
+func (m *money) Init() {
+	m.ready = m.owner != nil && m.pet != nil
+}
+
+type wallet struct {
+	cash *money
+}
+
+func (w *wallet) Constructor() *wallet {
+	return &wallet{}
+}
+
+func (w *wallet) InjectCash(cash *money) error {
+	if cash.currency == "" {
+		return errors.New("cash has no currency")
+	}
+	w.cash = cash
+	return nil
+}
+
+func TestMethodInjection(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test method injection", InjectMethodPrefix: "Inject"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterInstance[Person](container, &Human{Name: "peter"}))
+	assert.Nil(t, RegisterSingleton[Animal, Dog](container, "rex"))
+	assert.Nil(t, RegisterTransient[money, money](container))
+	assert.Nil(t, RegisterTransient[wallet, wallet](container))
+
+	assert.Nil(t, container.Validate())
+
+	m, err := GetFromContainer[*money]("test method injection")
+	assert.Nil(t, err, "error getting money")
+	assert.Equal(t, "peter", m.owner.(*Human).Name)
+	assert.IsType(t, &Dog{}, m.pet)
+	assert.True(t, m.ready, "inject methods should be called before the init func")
+
+	// inject methods of constructed dependencies are called after the constructor, errors are wrapped with the dependency chain
+	_, err = GetFromContainer[*wallet]("test method injection")
+	assert.ErrorContains(t, err, "failed to call method 'InjectCash' of dependency 'github.com/Gobusters/ectoinject.wallet'. Dependency chain: github.com/Gobusters/ectoinject.wallet: cash has no currency")
+}
+
+func TestMethodInjectionMissingDependency(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test method injection missing dependency", InjectMethodPrefix: "Inject"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterTransient[money, money](container))
+
+	err = container.Validate()
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "InjectOwner")
+
+	_, err = GetFromContainer[*money]("test method injection missing dependency")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMethodInjectionDisabled(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test method injection disabled"})
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterTransient[money, money](container))
+
+	m, err := GetFromContainer[*money]("test method injection disabled")
+	assert.Nil(t, err, "error getting money")
+	assert.Nil(t, m.owner, "inject methods should not be called without a prefix")
+}
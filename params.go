@@ -0,0 +1,12 @@
+package ectoinject
+
+import "github.com/Gobusters/ectoinject/dependency"
+
+// In marks a struct as a parameter object. When a constructor has an arg of a struct type that embeds In, each field of the struct is injected instead of the struct itself.
+// Fields are matched by their type or by the name in their inject tag. Fields tagged with `inject:",optional"` are left empty when their dependency is not registered.
+// context.Context fields get the context of the resolution
+type In = dependency.In
+
+// Out marks a struct as a result object. When a constructor returns a struct that embeds Out, or an instance func is registered for one, each field of the struct is registered as its own dependency.
+// Fields are named with their inject tag and fields tagged with "-" are skipped. Every field shares one call of the constructor and the lifecycle of the registration
+type Out = dependency.Out
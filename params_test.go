@@ -0,0 +1,210 @@
+package ectoinject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+type crewParams struct {
+	In
+	Ctx     context.Context
+	Leader  Person `inject:"peter"`
+	Member  Person `inject:"ray"`
+	Pet     Animal `inject:"slimer,optional"`
+	Ignored Person `inject:"-"`
+}
+
+type crew struct {
+	leader Person
+	member Person
+	pet    Animal
+	hasCtx bool
+}
+
+func (c *crew) Constructor(params crewParams) *crew {
+	return &crew{leader: params.Leader, member: params.Member, pet: params.Pet, hasCtx: params.Ctx != nil}
+}
+
+type pointerCrew struct {
+	leader Person
+}
+
+func (c *pointerCrew) Constructor(params *crewParams) (*pointerCrew, error) {
+	return &pointerCrew{leader: params.Leader}, nil
+}
+
+func TestConstructorParamObject(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test constructor param object", AllowMissingDependencies: true})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstanceFunc[Person](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		return &Human{Name: "peter"}, nil
+	}, "peter")
+	assert.Nil(t, err, "error registering peter")
+
+	err = RegisterSingleton[crew, crew](container)
+	assert.Nil(t, err, "error registering crew")
+
+	err = RegisterSingleton[pointerCrew, pointerCrew](container)
+	assert.Nil(t, err, "error registering pointer crew")
+
+	err = RegisterInstanceFunc[Person](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		return &Human{Name: "ray"}, nil
+	}, "ray")
+	assert.Nil(t, err, "error registering ray")
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test constructor param object")
+	assert.Nil(t, err, "error setting active container")
+
+	_, c, err := GetContext[*crew](ctx)
+	assert.Nil(t, err, "error getting crew")
+	assert.Equal(t, "peter", c.leader.(*Human).Name)
+	assert.Equal(t, "ray", c.member.(*Human).Name)
+	assert.Nil(t, c.pet, "optional dependency should be left empty")
+	assert.True(t, c.hasCtx, "context was not injected")
+
+	_, pc, err := GetContext[*pointerCrew](ctx)
+	assert.Nil(t, err, "error getting pointer crew")
+	assert.Equal(t, "peter", pc.leader.(*Human).Name)
+}
+
+func TestConstructorParamObjectMissingDependency(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test constructor param object missing dependency", AllowMissingDependencies: true})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstanceFunc[Person](container, lifecycles.Singleton, func(ctx context.Context) (any, error) {
+		return &Human{Name: "peter"}, nil
+	}, "peter")
+	assert.Nil(t, err, "error registering peter")
+
+	err = RegisterSingleton[crew, crew](container)
+	assert.Nil(t, err, "error registering crew")
+
+	err = RegisterSingleton[pointerCrew, pointerCrew](container)
+	assert.Nil(t, err, "error registering pointer crew")
+
+	// ray is not optional
+	err = container.Validate()
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "dependency 'github.com/Gobusters/ectoinject.crew' has unregistered dependency 'ray' in 'Constructor' func")
+	assert.NotContains(t, err.Error(), "slimer")
+
+	_, _, err = container.Get(context.Background(), "github.com/Gobusters/ectoinject.crew")
+	var notFound *NotFoundError
+	if assert.ErrorAs(t, err, &notFound) {
+		assert.Equal(t, "ray", notFound.Dependency)
+		assert.Equal(t, "Constructor", notFound.Func)
+	}
+}
+
+type poolLog struct {
+	calls  int
+	closed []string
+}
+
+type pool struct {
+	name string
+	log  *poolLog
+}
+
+func (p *pool) Close() error {
+	p.log.closed = append(p.log.closed, p.name)
+	return nil
+}
+
+type storageResult struct {
+	Out
+	Primary *pool `inject:"primary"`
+	Replica *pool `inject:"replica"`
+	Skipped *pool `inject:"-"`
+}
+
+type storage struct{}
+
+func (s *storage) Constructor(log *poolLog) (storageResult, error) {
+	log.calls++
+	return storageResult{
+		Primary: &pool{name: "primary", log: log},
+		Replica: &pool{name: "replica", log: log},
+	}, nil
+}
+
+type poolConsumer struct {
+	Primary *pool `inject:"primary"`
+	Replica *pool `inject:"replica"`
+}
+
+func TestConstructorResultObject(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test constructor result object"})
+	assert.Nil(t, err, "error creating container")
+
+	log := &poolLog{}
+	err = RegisterInstance[*poolLog](container, log)
+	assert.Nil(t, err, "error registering pool log")
+
+	err = RegisterSingleton[storage, storage](container)
+	assert.ErrorContains(t, err, "dependency 'github.com/Gobusters/ectoinject.storage' has a 'Constructor' func that returns the result struct 'github.com/Gobusters/ectoinject.storageResult'")
+
+	err = RegisterSingleton[storageResult, storage](container)
+	assert.Nil(t, err, "error registering storage")
+
+	err = RegisterTransient[poolConsumer, poolConsumer](container)
+	assert.Nil(t, err, "error registering pool consumer")
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test constructor result object")
+	assert.Nil(t, err, "error setting active container")
+
+	_, repo, err := GetContext[*poolConsumer](ctx)
+	assert.Nil(t, err, "error getting pool consumer")
+	assert.Equal(t, "primary", repo.Primary.name)
+	assert.Equal(t, "replica", repo.Replica.name)
+
+	_, primary, err := GetNamedDependency[*pool](ctx, "primary")
+	assert.Nil(t, err, "error getting primary pool")
+	assert.Same(t, repo.Primary, primary, "fields should share the singleton result")
+	assert.Equal(t, 1, log.calls, "constructor should be called once")
+
+	_, result, err := GetContext[storageResult](ctx)
+	assert.Nil(t, err, "error getting storage result")
+	assert.Same(t, primary, result.Primary, "the result struct should be registered")
+
+	_, _, err = GetNamedDependency[*pool](ctx, "Skipped")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = container.Close(context.Background())
+	assert.Nil(t, err, "error closing container")
+	assert.Equal(t, []string{"replica", "primary"}, log.closed, "fields should be disposed in reverse order")
+}
+
+func TestInstanceFuncResultObject(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test instance func result object"})
+	assert.Nil(t, err, "error creating container")
+
+	calls := 0
+	err = RegisterInstanceFunc[storageResult](container, lifecycles.Scoped, func(ctx context.Context) (any, error) {
+		calls++
+		return storageResult{Primary: &pool{name: "primary"}, Replica: &pool{name: "replica"}}, nil
+	})
+	assert.Nil(t, err, "error registering storage result")
+
+	ctx, err := SetActiveContainer(context.Background(), "test instance func result object")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, _ = NewScope(ctx)
+	ctx, primary, err := GetNamedDependency[*pool](ctx, "primary")
+	assert.Nil(t, err, "error getting primary pool")
+	assert.Equal(t, "primary", primary.name)
+
+	_, replica, err := GetNamedDependency[*pool](ctx, "replica")
+	assert.Nil(t, err, "error getting replica pool")
+	assert.Equal(t, "replica", replica.name)
+	assert.Equal(t, 1, calls, "instance func should be called once per scope")
+}
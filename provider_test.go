@@ -0,0 +1,181 @@
+package ectoinject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+// gateway stands in for a third party type that cannot have a constructor
+type gateway struct {
+	owner  Person
+	hasCtx bool
+	hasDI  bool
+}
+
+var gatewayCalls int
+
+func newGateway(ctx context.Context, di ectocontainer.DIContainer, owner Person) (*gateway, error) {
+	gatewayCalls++
+	return &gateway{owner: owner, hasCtx: ctx != nil, hasDI: di != nil}, nil
+}
+
+func TestRegisterProvider(t *testing.T) {
+	gatewayCalls = 0
+
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test register provider"})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterInstance[Person](container, &Human{Name: "peter"})
+	assert.Nil(t, err, "error registering person")
+
+	err = RegisterProvider(container, lifecycles.Singleton, newGateway)
+	assert.Nil(t, err, "error registering gateway provider")
+
+	err = RegisterProvider(container, lifecycles.Transient, func(s *gateway) int { return 42 }, "answer")
+	assert.Nil(t, err, "error registering answer provider")
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test register provider")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, s, err := GetContext[*gateway](ctx)
+	assert.Nil(t, err, "error getting gateway")
+	assert.Equal(t, "peter", s.owner.(*Human).Name)
+	assert.True(t, s.hasCtx, "context was not injected")
+	assert.True(t, s.hasDI, "container was not injected")
+
+	_, answer, err := GetNamedDependency[int](ctx, "answer")
+	assert.Nil(t, err, "error getting answer")
+	assert.Equal(t, 42, answer)
+	assert.Equal(t, 1, gatewayCalls, "singleton provider should be called once")
+}
+
+func TestRegisterProviderErrors(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test register provider errors"})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterProvider(container, lifecycles.Singleton, "gateway")
+	assert.EqualError(t, err, "provider must be a func but is string")
+
+	err = RegisterProvider(container, lifecycles.Singleton, func() {})
+	assert.ErrorContains(t, err, "must return the provided type, optionally a cleanup func and optionally an error")
+
+	err = RegisterProvider(container, lifecycles.Singleton, func() (*gateway, string) { return nil, "" })
+	assert.ErrorContains(t, err, "must return the provided type, optionally a cleanup func and optionally an error")
+
+	err = RegisterProvider(container, "forever", newGateway)
+	assert.EqualError(t, err, "invalid lifecycle 'forever' must be one of [transient singleton scoped]")
+
+	err = RegisterProvider(container, lifecycles.Singleton, newGateway)
+	assert.Nil(t, err, "error registering gateway provider")
+
+	err = container.Validate()
+	assert.ErrorContains(t, err, "dependency 'github.com/Gobusters/ectoinject.gateway' has unregistered dependency 'github.com/Gobusters/ectoinject.Person' in 'github.com/Gobusters/ectoinject.newGateway' func")
+
+	err = RegisterProvider(container, lifecycles.Singleton, func() (*Human, error) { return nil, errors.New("no humans") })
+	assert.Nil(t, err, "error registering human provider")
+
+	_, err = GetFromContainer[*Human]("test register provider errors")
+	var constructorErr *ConstructorError
+	assert.ErrorAs(t, err, &constructorErr)
+	assert.ErrorContains(t, err, "no humans")
+}
+
+type connection struct {
+	log *eventLog
+}
+
+func (c *connection) Constructor() (*connection, func(), error) {
+	log := &eventLog{}
+	return &connection{log: log}, func() { log.add("connection") }, nil
+}
+
+type session struct {
+	conn *connection
+}
+
+type transaction struct {
+	id   int
+	sess *session
+}
+
+func TestProviderCleanup(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test provider cleanup"})
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterSingleton[connection, connection](container)
+	assert.Nil(t, err, "error registering connection")
+
+	err = RegisterProvider(container, lifecycles.Scoped, func(conn *connection) (*session, func()) {
+		return &session{conn: conn}, func() { conn.log.add("session") }
+	})
+	assert.Nil(t, err, "error registering session provider")
+
+	ids := 0
+	err = RegisterProvider(container, lifecycles.Transient, func(sess *session) (*transaction, func(), error) {
+		ids++
+		tx := &transaction{id: ids, sess: sess}
+		return tx, func() { sess.conn.log.add(fmt.Sprintf("transaction %d", tx.id)) }, nil
+	})
+	assert.Nil(t, err, "error registering transaction provider")
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), "test provider cleanup")
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, s := NewScope(ctx)
+	ctx, first, err := GetContext[*transaction](ctx)
+	assert.Nil(t, err, "error getting first transaction")
+	_, second, err := GetContext[*transaction](ctx)
+	assert.Nil(t, err, "error getting second transaction")
+	assert.Same(t, first.sess, second.sess, "session should be shared by the scope")
+	assert.Equal(t, 1, s.Len(), "transient instances are not cached by the scope")
+
+	log := first.sess.conn.log
+	err = s.Close(context.Background())
+	assert.Nil(t, err, "error closing scope")
+	assert.Equal(t, []string{"transaction 2", "transaction 1", "session"}, log.get(), "cleanups should run in reverse order when the scope ends")
+
+	err = container.Close(context.Background())
+	assert.Nil(t, err, "error closing container")
+	assert.Equal(t, []string{"transaction 2", "transaction 1", "session", "connection"}, log.get(), "singleton cleanups should run when the container closes")
+}
+
+func TestTransientCleanupWithoutScope(t *testing.T) {
+	container, err := NewDIContainer(ectocontainer.DIContainerConfig{ID: "test transient cleanup without scope"})
+	assert.Nil(t, err, "error creating container")
+
+	log := &eventLog{}
+	err = RegisterProvider(container, lifecycles.Transient, func() (*session, func()) {
+		return &session{}, func() { log.add("session") }
+	})
+	assert.Nil(t, err, "error registering session provider")
+
+	ctx, err := SetActiveContainer(context.Background(), "test transient cleanup without scope")
+	assert.Nil(t, err, "error setting active container")
+
+	// a scope is added to the context so the cleanup can be run
+	ctx, _, err = GetContext[*session](ctx)
+	assert.Nil(t, err, "error getting session")
+
+	s, ok := GetScope(ctx)
+	assert.True(t, ok, "scope was not added to the context")
+	assert.Nil(t, s.Close(context.Background()))
+	assert.Equal(t, []string{"session"}, log.get())
+
+	// sessions resolved with Get are not tracked by the root scope of the container, so they do not pile up until it is closed
+	for i := 0; i < 100; i++ {
+		_, err = GetFromContainer[*session]("test transient cleanup without scope")
+		assert.Nil(t, err, "error getting session")
+	}
+	assert.Nil(t, container.Close(context.Background()))
+	assert.Equal(t, []string{"session"}, log.get(), "cleanups of sessions resolved with Get should not be run")
+}
@@ -62,17 +62,100 @@ func RegisterInstanceFunc[TType any](container ectocontainer.DIContainer, lifecy
 	return nil
 }
 
-// RegisterInstance registers an instance in the container. Instances are treated as singletons
+// RegisterProvider registers a provider func in the container. The args of the provider are resolved from the container the same way as the args of a constructor,
+// and its first result is the dependency. Use providers for types you cannot add a constructor to
+// container: The container to register the dependency in
+// lifecycle: The lifecycle of the dependency. Must be one of transient, scoped, or singleton
+// provider: a func such as `func(cfg *Config, log Logger) (*Server, error)`. Must return the dependency and optionally an error
+// names: (optional) The names of the dependency
+func RegisterProvider(container ectocontainer.DIContainer, lifecycle string, provider any, names ...string) error {
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	for _, name := range names {
+		// create a new dependency
+		dep, err := dependency.NewProviderDependency(name, lifecycle, provider)
+		if err != nil {
+			return err
+		}
+
+		// add the dependency to the container
+		container.AddDependency(dep)
+	}
+	return nil
+}
+
+// RegisterFactory registers a factory func type in the container. The container creates the func, which builds a new TValue every time it is called.
+// The args of the func are passed to the constructor args of TValue with the same type, or to the fields tagged with the assisted option if TValue does not have a constructor.
+// Every other dependency of TValue is resolved from the container and the scope the func was resolved in
+// TFactory: The func type of the factory, such as `func(tenantID string) (*ReportJob, error)`. Must return the created type and an error
+// TValue: The struct created by the factory
+// container: The container to register the factory in
+// lifecycle: The lifecycle of the factory func. Must be one of transient, scoped, or singleton
+// names: (optional) The names of the factory
+func RegisterFactory[TFactory any, TValue any](container ectocontainer.DIContainer, lifecycle string, names ...string) error {
+	if len(names) == 0 {
+		names = []string{""}
+	}
+	valueType := reflect.TypeOf((*TValue)(nil)).Elem()
+	for _, name := range names {
+		// create a new dependency
+		dep, err := dependency.NewFactoryDependency[TFactory](name, lifecycle, container.GetConstructorFuncName(), valueType)
+		if err != nil {
+			return err
+		}
+
+		// add the dependency to the container
+		container.AddDependency(dep)
+	}
+	return nil
+}
+
+// RegisterDecorator registers a decorator for every registration of T, or of the pointer or element type of T. Each time the container builds an instance of T, the instance is passed through
+// the decorators of T in registration order, so a singleton is decorated once and a transient on every resolution. The context passed to the decorator
+// can be used to get other dependencies. Use RegisterDecoratorFunc to have the dependencies of the decorator checked by Validate
+// T: The type of the decorated dependencies
+// container: The container to register the decorator in
+// decorator: a func that wraps the instance, such as a caching or metrics layer
+func RegisterDecorator[T any](container ectocontainer.DIContainer, decorator func(ctx context.Context, inner T) (T, error)) error {
+	return RegisterDecoratorFunc[T](container, decorator)
+}
+
+// RegisterDecoratorFunc registers a decorator func for every registration of T. The first arg of type T gets the instance being decorated,
+// and every other arg is resolved from the container the same way as the args of a constructor
+// T: The type of the decorated dependencies
+// container: The container to register the decorator in
+// decorator: a func such as `func(inner Repository, metrics *Metrics) (Repository, error)`. Must return T and optionally an error
+func RegisterDecoratorFunc[T any](container ectocontainer.DIContainer, decorator any) error {
+	dec, err := dependency.NewDecorator(reflect.TypeOf((*T)(nil)).Elem(), decorator)
+	if err != nil {
+		return err
+	}
+
+	container.AddDecorator(dec)
+	return nil
+}
+
+// RegisterInstance registers an instance in the container. Instances are treated as singletons. The container does not dispose instances when it is closed
 // TType: The type of the dependency
 // container: The container to register the dependency in
 // instance: The instance to register
 // names: (optional) The names of the dependency
 func RegisterInstance[TType any](container ectocontainer.DIContainer, instance any, names ...string) error {
-	getInstanceFunc := func(context.Context) (any, error) {
-		return instance, nil
+	if len(names) == 0 {
+		names = []string{""}
 	}
+	for _, name := range names {
+		// create a new dependency
+		dep, err := dependency.NewInstanceDependency[TType](name, instance)
+		if err != nil {
+			return err
+		}
 
-	return RegisterInstanceFunc[TType](container, lifecycles.Singleton, getInstanceFunc, names...)
+		// add the dependency to the container
+		container.AddDependency(dep)
+	}
+	return nil
 }
 
 // RegisterDependency registers a dependency in the container
@@ -87,8 +170,9 @@ func RegisterDependency[TType any, TValue any](container ectocontainer.DIContain
 		return fmt.Errorf("dependency '%s' has type '%s' which is not a struct. Please register the dependnecy using RegisterInstance or RegisterInstanceFunc", valueType.Name(), valueType.Name())
 	}
 
-	// Ensure
-	if !ectoreflect.SameType[TType, TValue]() {
+	// Ensure TValue can be used as TType. A constructor that returns a result struct is registered as the result struct
+	_, isResult := dependency.GetResultType(valueType, container.GetConstructorFuncName())
+	if !isResult && !ectoreflect.SameType[TType, TValue]() {
 		return fmt.Errorf("type '%s' is not assignable to '%s'", ectoreflect.GetReflectTypeName(valueType), ectoreflect.GetIntefaceName[TType]())
 	}
 
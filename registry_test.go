@@ -0,0 +1,101 @@
+package ectoinject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/stretchr/testify/assert"
+)
+
+type settings struct {
+	Source string
+}
+
+type box[T any] struct {
+	Value T `inject:"-"`
+}
+
+type settingsConsumer struct {
+	Tags     []string       `inject:""`
+	Counts   map[string]int `inject:""`
+	Value    settings       `inject:""`
+	Pointer  *settings      `inject:""`
+	IntBox   *box[int]      `inject:""`
+	Callback func() string  `inject:""`
+}
+
+func TestRegistrationsAreKeyedByType(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test registrations are keyed by type",
+		AllowMissingDependencies: false,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	// every one of these types used to be registered with the same name as another one
+	assert.Nil(t, RegisterInstance[[]string](container, []string{"a", "b"}))
+	assert.Nil(t, RegisterInstance[map[string]int](container, map[string]int{"a": 1}))
+	assert.Nil(t, RegisterInstance[settings](container, settings{Source: "value"}))
+	assert.Nil(t, RegisterInstance[*settings](container, &settings{Source: "pointer"}))
+	assert.Nil(t, RegisterInstance[func() string](container, func() string { return "called" }))
+	assert.Nil(t, RegisterSingleton[box[int], box[int]](container))
+	assert.Nil(t, RegisterSingleton[box[string], box[string]](container))
+	assert.Nil(t, RegisterSingleton[settingsConsumer, settingsConsumer](container))
+
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, consumer, err := GetContext[*settingsConsumer](ctx)
+	assert.Nil(t, err, "error getting consumer")
+	assert.Equal(t, []string{"a", "b"}, consumer.Tags)
+	assert.Equal(t, map[string]int{"a": 1}, consumer.Counts)
+	assert.Equal(t, "value", consumer.Value.Source)
+	assert.Equal(t, "pointer", consumer.Pointer.Source)
+	assert.NotNil(t, consumer.IntBox)
+	assert.Equal(t, "called", consumer.Callback())
+
+	_, value, err := GetContext[settings](ctx)
+	assert.Nil(t, err, "error getting settings")
+	assert.Equal(t, "value", value.Source)
+
+	_, pointer, err := GetContext[*settings](ctx)
+	assert.Nil(t, err, "error getting settings pointer")
+	assert.Equal(t, "pointer", pointer.Source)
+}
+
+func TestGetByName(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test get by name",
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterSingleton[Animal, Dog](container))
+	assert.Nil(t, RegisterSingleton[Person, Human](container, "john"))
+	assert.Nil(t, RegisterInstance[settings](container, settings{Source: "value"}))
+	assert.Nil(t, RegisterInstance[*settings](container, &settings{Source: "pointer"}))
+
+	// unnamed dependencies are named after their type
+	_, dog, err := container.Get(context.Background(), "github.com/Gobusters/ectoinject.Animal")
+	assert.Nil(t, err, "error getting dog")
+	assert.IsType(t, &Dog{}, dog)
+
+	_, john, err := container.Get(context.Background(), "john")
+	assert.Nil(t, err, "error getting john")
+	assert.IsType(t, &Human{}, john)
+
+	// a named dependency can be requested as a different type
+	_, person, err := GetDependency[Person](context.Background(), container, "john")
+	assert.Nil(t, err, "error getting john as a person")
+	assert.NotNil(t, person)
+
+	// settings and *settings are both named after settings
+	_, _, err = container.Get(context.Background(), "github.com/Gobusters/ectoinject.settings")
+	assert.ErrorContains(t, err, "dependency name 'github.com/Gobusters/ectoinject.settings' is registered for more than one type: ectoinject.settings, *ectoinject.settings")
+}
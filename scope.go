@@ -0,0 +1,28 @@
+package ectoinject
+
+import (
+	"context"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/internal/scope"
+)
+
+// NewScope starts a new scope. Scoped dependencies resolved with the returned context are cached by the scope until it is closed.
+// Closing the scope disposes the scoped instances that implement io.Closer and runs the cleanup funcs of the scoped and transient instances created with it in reverse creation order.
+// The scope is safe to use across goroutines
+// ctx: The context to start the scope in
+func NewScope(ctx context.Context) (context.Context, ectocontainer.DIScope) {
+	s := scope.New()
+	return scope.WithScope(ctx, s), s
+}
+
+// GetScope gets the active scope from the context. Returns the scope and a bool indicating if the context has a scope
+// ctx: The context to get the scope from
+func GetScope(ctx context.Context) (ectocontainer.DIScope, bool) {
+	s, ok := scope.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	return s, true
+}
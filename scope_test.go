@@ -0,0 +1,188 @@
+package ectoinject
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/Gobusters/ectoinject/lifecycles"
+	"github.com/stretchr/testify/assert"
+)
+
+// eventLog records the order of lifecycle events
+type eventLog struct {
+	mu     sync.Mutex
+	closed []string
+}
+
+func (l *eventLog) add(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = append(l.closed, name)
+}
+
+func (l *eventLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.closed...)
+}
+
+type closer struct {
+	name string
+	log  *eventLog
+	err  error
+}
+
+func (c *closer) Close() error {
+	c.log.add(c.name)
+	return c.err
+}
+
+func registerClosers(t *testing.T, container ectocontainer.DIContainer, lifecycle string, log *eventLog, names ...string) {
+	for _, name := range names {
+		err := RegisterInstanceFunc[*closer](container, lifecycle, func(ctx context.Context) (any, error) {
+			return &closer{name: name, log: log}, nil
+		}, name)
+		assert.Nil(t, err, "error registering closer")
+	}
+}
+
+func TestNewScope(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test new scope",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	log := &eventLog{}
+	registerClosers(t, container, lifecycles.Scoped, log, "first", "second")
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, s := NewScope(ctx)
+	active, ok := GetScope(ctx)
+	assert.True(t, ok, "scope was not added to the context")
+	assert.Equal(t, s, active, "active scope does not match")
+
+	_, first, err := GetNamedDependency[*closer](ctx, "first")
+	assert.Nil(t, err, "error getting first closer")
+	_, second, err := GetNamedDependency[*closer](ctx, "second")
+	assert.Nil(t, err, "error getting second closer")
+
+	// a derived context shares the scope
+	derived := context.WithValue(ctx, contextKey("derived"), true)
+	_, sameFirst, err := GetNamedDependency[*closer](derived, "first")
+	assert.Nil(t, err, "error getting first closer")
+	assert.Same(t, first, sameFirst, "derived context did not share the scope")
+	assert.Equal(t, 2, s.Len())
+
+	err = s.Close(context.Background())
+	assert.Nil(t, err, "error closing scope")
+	assert.True(t, s.IsClosed(), "scope was not closed")
+	assert.Equal(t, []string{"second", "first"}, log.get(), "scoped instances were not disposed in reverse creation order")
+	assert.NotSame(t, first, second)
+
+	_, _, err = GetNamedDependency[*closer](ctx, "first")
+	assert.ErrorContains(t, err, "scope is closed")
+}
+
+func TestCloseScopeErrors(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test close scope errors",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	log := &eventLog{}
+	for _, name := range []string{"first", "second", "third"} {
+		err = RegisterInstanceFunc[*closer](container, lifecycles.Scoped, func(ctx context.Context) (any, error) {
+			return &closer{name: name, log: log, err: fmt.Errorf("%s failed", name)}, nil
+		}, name)
+		assert.Nil(t, err, "error registering closer")
+	}
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, s := NewScope(ctx)
+	for _, name := range []string{"first", "second", "third"} {
+		_, _, err = GetNamedDependency[*closer](ctx, name)
+		assert.Nil(t, err, "error getting closer")
+	}
+
+	err = s.Close(context.Background())
+	assert.ErrorContains(t, err, "failed to dispose dependency 'first': first failed")
+	assert.ErrorContains(t, err, "failed to dispose dependency 'third': third failed")
+	assert.Equal(t, []string{"third", "second", "first"}, log.get(), "every instance should be disposed")
+
+	// closing again is a no-op
+	assert.Nil(t, s.Close(context.Background()))
+}
+
+func TestConcurrentScope(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test concurrent scope",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterScoped[Person, Human](container)
+	assert.Nil(t, err, "error registering person dependency")
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	ctx, s := NewScope(ctx)
+
+	var wg sync.WaitGroup
+	people := make([]Person, 20)
+	for i := range people {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, person, err := GetContext[Person](ctx)
+			assert.Nil(t, err, "error getting person")
+			people[i] = person
+		}()
+	}
+	wg.Wait()
+
+	for i := range people {
+		assert.Same(t, people[0], people[i], "scoped instance was built more than once")
+	}
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestGetFromContainerScoped(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{
+		ID:                       "test get from container scoped",
+		AllowCaptiveDependencies: true,
+		AllowMissingDependencies: true,
+	}
+
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterScoped[Person, Human](container)
+	assert.Nil(t, err, "error registering person dependency")
+
+	person, err := GetFromContainer[Person](config.ID)
+	assert.Nil(t, err, "error getting person")
+	assert.Equal(t, 1, person.Count())
+
+	person, err = GetFromContainer[Person](config.ID)
+	assert.Nil(t, err, "error getting person")
+	assert.Equal(t, 2, person.Count(), "scoped dependency was not cached by the root scope")
+}
@@ -0,0 +1,82 @@
+package ectoinject
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Gobusters/ectoinject/ectocontainer"
+	"github.com/stretchr/testify/assert"
+)
+
+type kennel struct {
+	Guard Animal `inject:"guard,optional"`
+	Owner Person `inject:",required"`
+}
+
+type badKennel struct {
+	Guard Animal `inject:"guard,lazy"`
+}
+
+func TestInjectTagOptions(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{ID: "test inject tag options", AllowMissingDependencies: true}
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	err = RegisterTransient[kennel, kennel](container)
+	assert.Nil(t, err, "error registering kennel")
+
+	// required fields fail even though missing dependencies are allowed
+	err = container.Validate()
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorContains(t, err, "github.com/Gobusters/ectoinject.Person")
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, _, err = GetContext[*kennel](ctx)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = RegisterInstance[Person](container, &Human{Name: "peter"})
+	assert.Nil(t, err, "error registering person")
+
+	assert.Nil(t, container.Validate())
+
+	_, k, err := GetContext[*kennel](ctx)
+	assert.Nil(t, err, "error getting kennel")
+	assert.Nil(t, k.Guard, "optional field should be left empty")
+	assert.Equal(t, "peter", k.Owner.(*Human).Name)
+}
+
+func TestOptionalInjectTagWithoutMissingDependencies(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{ID: "test optional inject tag"}
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterInstance[Person](container, &Human{Name: "peter"}))
+	assert.Nil(t, RegisterTransient[kennel, kennel](container))
+	assert.Nil(t, container.Validate())
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, k, err := GetContext[*kennel](ctx)
+	assert.Nil(t, err, "error getting kennel")
+	assert.Nil(t, k.Guard, "optional field should be left empty")
+}
+
+func TestUnknownInjectTagOption(t *testing.T) {
+	config := ectocontainer.DIContainerConfig{ID: "test unknown inject tag option"}
+	container, err := NewDIContainer(config)
+	assert.Nil(t, err, "error creating container")
+
+	assert.Nil(t, RegisterTransient[badKennel, badKennel](container))
+
+	expected := "dependency 'github.com/Gobusters/ectoinject.badKennel' cannot be injected: field 'Guard' has unknown option 'lazy' in inject tag 'guard,lazy'. Options must be one of [optional required assisted inline]"
+	assert.EqualError(t, container.Validate(), expected)
+
+	ctx, err := SetActiveContainer(context.Background(), config.ID)
+	assert.Nil(t, err, "error setting active container")
+
+	_, _, err = GetContext[*badKennel](ctx)
+	assert.EqualError(t, err, expected)
+}